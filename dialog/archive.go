@@ -0,0 +1,514 @@
+package dialog
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/storage/repository"
+)
+
+// archiveScheme is the URI scheme of files and folders inside local archives.
+// URIs look like "archive+file:///home/me/photos.zip!/2025/img.jpg"; the root
+// of an archive has no "!/" suffix.
+const archiveScheme = "archive+file"
+
+var (
+	errNotArchive        = errors.New("not a supported archive")
+	errInvalidArchiveURI = errors.New("invalid archive URI")
+)
+
+func init() {
+	repository.Register(archiveScheme, &archiveRepository{})
+}
+
+// NewArchiveURI returns the root folder of a local ZIP or TAR (optionally gzip
+// compressed) archive, so its contents can be browsed like any other folder.
+func NewArchiveURI(archive fyne.URI) (fyne.ListableURI, error) {
+	if !isLocalArchive(archive) {
+		return nil, errNotArchive
+	}
+	root := &archiveURI{archive: archive.Path()}
+	if _, err := root.index(); err != nil {
+		return nil, err
+	}
+	return storage.ListerForURI(root)
+}
+
+// listerForURI is storage.ListerForURI that also opens local archives as folders.
+func listerForURI(u fyne.URI) (fyne.ListableURI, error) {
+	if l, err := storage.ListerForURI(u); err == nil {
+		return l, nil
+	}
+	return NewArchiveURI(u)
+}
+
+// isLocalArchive reports whether u is a local file with an archive extension.
+func isLocalArchive(u fyne.URI) bool {
+	return u.Scheme() == "file" && archiveKind(u.Name()) != ""
+}
+
+// archiveKind returns "zip", "tar" or "tgz" for supported archive names, or "".
+func archiveKind(name string) string {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return "zip"
+	case strings.HasSuffix(name, ".tar"):
+		return "tar"
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return "tgz"
+	}
+	return ""
+}
+
+// archiveURI identifies an entry of a local archive.
+type archiveURI struct {
+	archive string // slash separated path of the archive file
+	member  string // slash separated path inside the archive, "" for the root
+}
+
+func (u *archiveURI) Extension() string {
+	return path.Ext(u.Name())
+}
+
+func (u *archiveURI) Name() string {
+	if u.member == "" {
+		return path.Base(u.archive)
+	}
+	return path.Base(u.member)
+}
+
+func (u *archiveURI) MimeType() string {
+	if t := mime.TypeByExtension(u.Extension()); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+func (u *archiveURI) Scheme() string {
+	return archiveScheme
+}
+
+func (u *archiveURI) String() string {
+	return archiveScheme + "://" + u.Path()
+}
+
+func (u *archiveURI) Authority() string {
+	return ""
+}
+
+func (u *archiveURI) Path() string {
+	if u.member == "" {
+		return u.archive
+	}
+	return u.archive + "!/" + u.member
+}
+
+func (u *archiveURI) Query() string {
+	return ""
+}
+
+func (u *archiveURI) Fragment() string {
+	return ""
+}
+
+func (u *archiveURI) index() (*archiveIndex, error) {
+	return loadArchiveIndex(filepath.FromSlash(u.archive))
+}
+
+// archiveIndex lists the entries of an archive. Folders that only exist
+// implicitly, as the prefix of a member path, are included.
+type archiveIndex struct {
+	size    int64
+	modTime time.Time
+
+	dirs  map[string][]string // folder member path to its sorted child paths
+	files map[string]string   // file member path to its name in the archive
+}
+
+func (idx *archiveIndex) add(name string, isDir bool) {
+	member := cleanArchiveMember(name)
+	if member == "" {
+		return
+	}
+	if isDir {
+		idx.addDir(member)
+		return
+	}
+	if _, ok := idx.files[member]; ok {
+		return
+	}
+	idx.files[member] = name
+	idx.addChild(member)
+}
+
+func (idx *archiveIndex) addDir(member string) {
+	if _, ok := idx.dirs[member]; ok {
+		return
+	}
+	idx.dirs[member] = nil
+	if member != "" {
+		idx.addChild(member)
+	}
+}
+
+func (idx *archiveIndex) addChild(member string) {
+	parent := path.Dir(member)
+	if parent == "." {
+		parent = ""
+	}
+	idx.addDir(parent)
+	idx.dirs[parent] = append(idx.dirs[parent], member)
+}
+
+// cleanArchiveMember normalises a member name to a relative slash separated path.
+func cleanArchiveMember(name string) string {
+	return strings.Trim(path.Clean("/"+name), "/")
+}
+
+// maxArchiveIndexes is how many archive indexes are kept in memory.
+const maxArchiveIndexes = 16
+
+// archiveIndexes caches the index of the most recently used archives,
+// revalidated against the archive's size and modification time.
+var archiveIndexes = lruCache[*archiveIndex]{limit: maxArchiveIndexes}
+
+func loadArchiveIndex(file string) (*archiveIndex, error) {
+	stat, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+	if idx, ok := archiveIndexes.get(file); ok {
+		if idx.size == stat.Size() && idx.modTime.Equal(stat.ModTime()) {
+			return idx, nil
+		}
+	}
+
+	idx := &archiveIndex{
+		size:    stat.Size(),
+		modTime: stat.ModTime(),
+		dirs:    map[string][]string{"": nil},
+		files:   make(map[string]string),
+	}
+	switch archiveKind(file) {
+	case "zip":
+		r, err := zip.OpenReader(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range r.File {
+			idx.add(f.Name, f.FileInfo().IsDir())
+		}
+		r.Close()
+	case "tar", "tgz":
+		err := walkTar(file, func(hdr *tar.Header) bool {
+			mode := hdr.FileInfo().Mode()
+			if mode.IsDir() || mode.IsRegular() {
+				idx.add(hdr.Name, mode.IsDir())
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errNotArchive
+	}
+	for dir := range idx.dirs {
+		sort.Strings(idx.dirs[dir])
+	}
+
+	archiveIndexes.add(file, idx)
+	return idx, nil
+}
+
+// walkTar calls fn for each entry of a tar or tar.gz file until it returns false.
+func walkTar(file string, fn func(*tar.Header) bool) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if archiveKind(file) == "tgz" {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !fn(hdr) {
+			return nil
+		}
+	}
+}
+
+// archiveReader streams a single archive member.
+type archiveReader struct {
+	io.Reader
+	uri     fyne.URI
+	closers []io.Closer
+}
+
+func (r *archiveReader) URI() fyne.URI {
+	return r.uri
+}
+
+func (r *archiveReader) Close() error {
+	var err error
+	for i := len(r.closers) - 1; i >= 0; i-- {
+		if cErr := r.closers[i].Close(); err == nil {
+			err = cErr
+		}
+	}
+	return err
+}
+
+// archiveRepository is a read-only storage repository for the contents of
+// local ZIP and TAR archives.
+type archiveRepository struct{}
+
+var (
+	_ repository.ListableRepository     = (*archiveRepository)(nil)
+	_ repository.HierarchicalRepository = (*archiveRepository)(nil)
+	_ repository.CustomURIRepository    = (*archiveRepository)(nil)
+	_ ThumbnailKeyer                    = (*archiveRepository)(nil)
+)
+
+func toArchiveURI(u fyne.URI) (*archiveURI, error) {
+	if a, ok := u.(*archiveURI); ok {
+		return a, nil
+	}
+	// Listable wrappers from storage.ListerForURI hide the concrete type.
+	return parseArchiveURI(u.String())
+}
+
+// parseArchiveURI splits an archive URI at the first "!/" that follows an
+// existing file, so folders with a "!" in their name still resolve.
+func parseArchiveURI(s string) (*archiveURI, error) {
+	scheme, rest, ok := strings.Cut(s, "://")
+	if !ok || !strings.EqualFold(scheme, archiveScheme) || rest == "" {
+		return nil, errInvalidArchiveURI
+	}
+
+	first := -1
+	for i := 0; i < len(rest); {
+		j := strings.Index(rest[i:], "!/")
+		if j < 0 {
+			break
+		}
+		i += j
+		if first < 0 {
+			first = i
+		}
+		if stat, err := os.Stat(filepath.FromSlash(rest[:i])); err == nil && stat.Mode().IsRegular() {
+			first = i
+			break
+		}
+		i += 2
+	}
+	if first < 0 {
+		return &archiveURI{archive: strings.TrimSuffix(rest, "!")}, nil
+	}
+	return &archiveURI{archive: rest[:first], member: cleanArchiveMember(rest[first+2:])}, nil
+}
+
+func (r *archiveRepository) ParseURI(s string) (fyne.URI, error) {
+	return parseArchiveURI(s)
+}
+
+func (r *archiveRepository) Exists(u fyne.URI) (bool, error) {
+	a, err := toArchiveURI(u)
+	if err != nil {
+		return false, err
+	}
+	idx, err := a.index()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	_, isFile := idx.files[a.member]
+	_, isDir := idx.dirs[a.member]
+	return isFile || isDir, nil
+}
+
+func (r *archiveRepository) Reader(u fyne.URI) (fyne.URIReadCloser, error) {
+	a, err := toArchiveURI(u)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := a.index()
+	if err != nil {
+		return nil, err
+	}
+	name, ok := idx.files[a.member]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", a.member, os.ErrNotExist)
+	}
+
+	file := filepath.FromSlash(a.archive)
+	if archiveKind(file) == "zip" {
+		zr, err := zip.OpenReader(file)
+		if err != nil {
+			return nil, err
+		}
+		// zip.Reader.Open rejects names such as "./a" or "/a" that the index
+		// cleans, so the member is looked up by its name as stored.
+		for _, zf := range zr.File {
+			if zf.Name != name || zf.FileInfo().IsDir() {
+				continue
+			}
+			rc, err := zf.Open()
+			if err != nil {
+				zr.Close()
+				return nil, err
+			}
+			return &archiveReader{Reader: rc, uri: a, closers: []io.Closer{zr, rc}}, nil
+		}
+		zr.Close()
+		return nil, fmt.Errorf("%s: %w", a.member, os.ErrNotExist)
+	}
+
+	// Tar members can only be reached by reading up to them.
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	reader := &archiveReader{uri: a, closers: []io.Closer{f}}
+	var src io.Reader = f
+	if archiveKind(file) == "tgz" {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		reader.closers = append(reader.closers, gz)
+		src = gz
+	}
+	tr := tar.NewReader(src)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			reader.Close()
+			if err == io.EOF {
+				err = fmt.Errorf("%s: %w", a.member, os.ErrNotExist)
+			}
+			return nil, err
+		}
+		if hdr.Name == name && hdr.FileInfo().Mode().IsRegular() {
+			reader.Reader = tr
+			return reader, nil
+		}
+	}
+}
+
+func (r *archiveRepository) CanRead(u fyne.URI) (bool, error) {
+	return r.Exists(u)
+}
+
+func (r *archiveRepository) Destroy(string) {
+}
+
+func (r *archiveRepository) CanList(u fyne.URI) (bool, error) {
+	a, err := toArchiveURI(u)
+	if err != nil {
+		return false, err
+	}
+	idx, err := a.index()
+	if err != nil {
+		return false, err
+	}
+	_, ok := idx.dirs[a.member]
+	return ok, nil
+}
+
+func (r *archiveRepository) List(u fyne.URI) ([]fyne.URI, error) {
+	a, err := toArchiveURI(u)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := a.index()
+	if err != nil {
+		return nil, err
+	}
+	children, ok := idx.dirs[a.member]
+	if !ok {
+		return nil, repository.ErrOperationNotSupported
+	}
+
+	list := make([]fyne.URI, len(children))
+	for i, child := range children {
+		list[i] = &archiveURI{archive: a.archive, member: child}
+	}
+	return list, nil
+}
+
+func (r *archiveRepository) CreateListable(fyne.URI) error {
+	return repository.ErrOperationNotSupported
+}
+
+// Parent returns the containing folder inside the archive, or the folder
+// holding the archive file for its root.
+func (r *archiveRepository) Parent(u fyne.URI) (fyne.URI, error) {
+	a, err := toArchiveURI(u)
+	if err != nil {
+		return nil, err
+	}
+	if a.member == "" {
+		return storage.NewFileURI(filepath.Dir(filepath.FromSlash(a.archive))), nil
+	}
+	parent := path.Dir(a.member)
+	if parent == "." {
+		parent = ""
+	}
+	return &archiveURI{archive: a.archive, member: parent}, nil
+}
+
+func (r *archiveRepository) Child(u fyne.URI, component string) (fyne.URI, error) {
+	a, err := toArchiveURI(u)
+	if err != nil {
+		return nil, err
+	}
+	return &archiveURI{archive: a.archive, member: cleanArchiveMember(path.Join(a.member, component))}, nil
+}
+
+// ThumbnailKey identifies a member by the archive's size and modification
+// time, so thumbnails can be cached without reading the member.
+func (r *archiveRepository) ThumbnailKey(u fyne.URI) (string, error) {
+	a, err := toArchiveURI(u)
+	if err != nil {
+		return "", err
+	}
+	idx, err := a.index()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d:%d", idx.size, idx.modTime.UnixNano()), nil
+}
@@ -0,0 +1,229 @@
+package dialog
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/test"
+)
+
+var testArchiveMembers = map[string]string{
+	"readme.txt":          "hello",
+	"photos/2025/img.txt": "inner",
+	"./photos/cover.txt":  "cover",
+	"/photos/abs.txt":     "abs",
+}
+
+func writeTestZip(t *testing.T, file string) {
+	t.Helper()
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, body := range testArchiveMembers {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("zip create failed: %v", err)
+		}
+		io.WriteString(w, body)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip close failed: %v", err)
+	}
+}
+
+func writeTestTarGz(t *testing.T, file string) {
+	t.Helper()
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "photos/", Typeflag: tar.TypeDir, Mode: 0o755})
+	for name, body := range testArchiveMembers {
+		tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(body))})
+		io.WriteString(tw, body)
+	}
+	tw.WriteHeader(&tar.Header{Name: "photos/link", Typeflag: tar.TypeSymlink, Linkname: "cover.txt"})
+	tw.Close()
+	gz.Close()
+}
+
+func listNames(t *testing.T, dir fyne.ListableURI) []string {
+	t.Helper()
+	children, err := dir.List()
+	if err != nil {
+		t.Fatalf("list %s failed: %v", dir, err)
+	}
+	var names []string
+	for _, c := range children {
+		names = append(names, c.Name())
+	}
+	return names
+}
+
+func readArchiveMember(t *testing.T, u fyne.URI) string {
+	t.Helper()
+	r, err := storage.Reader(u)
+	if err != nil {
+		t.Fatalf("reader for %s failed: %v", u, err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read %s failed: %v", u, err)
+	}
+	return string(data)
+}
+
+func TestArchiveRepository_BrowseAndRead(t *testing.T) {
+	dir := t.TempDir()
+	writeTestZip(t, filepath.Join(dir, "photos.zip"))
+	writeTestTarGz(t, filepath.Join(dir, "photos.tar.gz"))
+
+	for _, name := range []string{"photos.zip", "photos.tar.gz"} {
+		t.Run(name, func(t *testing.T) {
+			root, err := listerForURI(storage.NewFileURI(filepath.Join(dir, name)))
+			if err != nil {
+				t.Fatalf("archive did not open as a folder: %v", err)
+			}
+			if root.Name() != name {
+				t.Errorf("root name = %q", root.Name())
+			}
+			if got := listNames(t, root); len(got) != 2 || got[0] != "photos" || got[1] != "readme.txt" {
+				t.Fatalf("root listing = %v", got)
+			}
+
+			// Folders only implied by member paths are listable too.
+			inner, err := storage.ParseURI(root.String() + "!/photos/2025")
+			if err != nil {
+				t.Fatalf("parse failed: %v", err)
+			}
+			innerDir, err := storage.ListerForURI(inner)
+			if err != nil {
+				t.Fatalf("implicit folder not listable: %v", err)
+			}
+			children, _ := innerDir.List()
+			if len(children) != 1 || children[0].Name() != "img.txt" {
+				t.Fatalf("inner listing = %v", children)
+			}
+			if got := readArchiveMember(t, children[0]); got != "inner" {
+				t.Errorf("member content = %q", got)
+			}
+			if isDir, _ := storage.CanList(children[0]); isDir {
+				t.Error("a file member should not be listable")
+			}
+
+			// A parsed URI round trips and the parent chain leads out of the archive.
+			parsed, err := storage.ParseURI(children[0].String())
+			if err != nil || parsed.String() != children[0].String() {
+				t.Fatalf("round trip = %v, %v", parsed, err)
+			}
+			parent, _ := storage.Parent(children[0])
+			parent, _ = storage.Parent(parent)
+			if parent.String() != root.String()+"!/photos" {
+				t.Errorf("grandparent = %s", parent)
+			}
+			parent, _ = storage.Parent(parent)
+			if parent.String() != root.String() {
+				t.Errorf("archive root parent chain reached %s", parent)
+			}
+			if outside, _ := storage.Parent(parent); outside.String() != storage.NewFileURI(dir).String() {
+				t.Errorf("parent of the archive root = %s, want its folder", outside)
+			}
+
+			// Members stored under names that are not valid fs paths still read.
+			for member, want := range map[string]string{"photos/cover.txt": "cover", "photos/abs.txt": "abs"} {
+				u, _ := storage.ParseURI(root.String() + "!/" + member)
+				if got := readArchiveMember(t, u); got != want {
+					t.Errorf("%s content = %q, want %q", member, got, want)
+				}
+			}
+
+			if _, err := storage.Reader(&archiveURI{archive: filepath.ToSlash(filepath.Join(dir, name)), member: "missing.txt"}); err == nil {
+				t.Error("expected an error reading a missing member")
+			}
+		})
+	}
+}
+
+func TestFileDialog_SetLocationInsideArchive(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	file := filepath.Join(t.TempDir(), "a.zip")
+	writeTestZip(t, file)
+	root, err := NewArchiveURI(storage.NewFileURI(file))
+	if err != nil {
+		t.Fatalf("NewArchiveURI failed: %v", err)
+	}
+
+	d := NewFileOpen(func([]fyne.URIReadCloser, error) {}, a.NewWindow("Test"), true).(*fileDialog)
+	d.fileList = newFileList(d)
+	d.SetLocation(root)
+	if len(d.fileList.filtered) != 2 {
+		t.Fatalf("expected 2 entries in the archive root, got %v", d.fileList.filtered)
+	}
+
+	if _, err := NewArchiveURI(storage.NewFileURI(filepath.Join(t.TempDir(), "plain.txt"))); err == nil {
+		t.Error("expected an error for a non-archive file")
+	}
+}
+
+func TestFileDialog_OpenArchiveInBackground(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "photos.tar.gz")
+	writeTestTarGz(t, file)
+	lister, _ := storage.ListerForURI(storage.NewFileURI(dir))
+
+	locations := make(chan fyne.ListableURI, 4)
+	d := NewFileOpenURIsWithOptions(func([]fyne.URI, error) {}, a.NewWindow("Test"), Options{
+		Location:          lister,
+		OnLocationChanged: func(dir fyne.ListableURI) { locations <- dir },
+	}).(*fileDialog)
+	d.Show()
+	<-locations
+
+	d.openArchive(storage.NewFileURI(file), 0)
+	select {
+	case root := <-locations:
+		if root.Scheme() != archiveScheme || root.Name() != "photos.tar.gz" {
+			t.Fatalf("entered %s, want the archive root", root)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("archive was not opened")
+	}
+	if d.busy.Visible() {
+		t.Error("expected the busy indicator to be hidden once the archive is listed")
+	}
+}
+
+func TestArchiveIndexes_Bounded(t *testing.T) {
+	dir := t.TempDir()
+	for i := range maxArchiveIndexes + 4 {
+		file := filepath.Join(dir, fmt.Sprintf("a%d.zip", i))
+		writeTestZip(t, file)
+		if _, err := loadArchiveIndex(file); err != nil {
+			t.Fatalf("index %s failed: %v", file, err)
+		}
+	}
+	if n := archiveIndexes.len(); n > maxArchiveIndexes {
+		t.Errorf("kept %d archive indexes, want at most %d", n, maxArchiveIndexes)
+	}
+}
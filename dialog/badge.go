@@ -0,0 +1,77 @@
+package dialog
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// mediaBadge is a small caption drawn over a thumbnail corner,
+// e.g. a video duration or an image resolution.
+type mediaBadge struct {
+	widget.BaseWidget
+
+	bg   *canvas.Rectangle
+	text *canvas.Text
+}
+
+func newMediaBadge() *mediaBadge {
+	b := &mediaBadge{
+		bg:   canvas.NewRectangle(color.NRGBA{A: 170}),
+		text: canvas.NewText("", color.White),
+	}
+	b.bg.CornerRadius = theme.InputRadiusSize()
+	b.text.TextSize = theme.CaptionTextSize()
+	b.ExtendBaseWidget(b)
+	b.Hide()
+	return b
+}
+
+// setText updates the caption, hiding the badge when text is empty.
+func (b *mediaBadge) setText(text string) {
+	if b.text.Text != text {
+		b.text.Text = text
+		b.text.Refresh()
+	}
+	if text == "" {
+		b.Hide()
+	}
+}
+
+func (b *mediaBadge) CreateRenderer() fyne.WidgetRenderer {
+	return &mediaBadgeRenderer{b: b}
+}
+
+type mediaBadgeRenderer struct {
+	b *mediaBadge
+}
+
+func (r *mediaBadgeRenderer) padding() fyne.Size {
+	return fyne.NewSize(theme.InnerPadding()/2, theme.InnerPadding()/8)
+}
+
+func (r *mediaBadgeRenderer) Layout(size fyne.Size) {
+	r.b.bg.Resize(size)
+	pad := r.padding()
+	r.b.text.Move(fyne.NewPos(pad.Width, pad.Height))
+	r.b.text.Resize(r.b.text.MinSize())
+}
+
+func (r *mediaBadgeRenderer) MinSize() fyne.Size {
+	pad := r.padding()
+	return r.b.text.MinSize().Add(fyne.NewSize(pad.Width*2, pad.Height*2))
+}
+
+func (r *mediaBadgeRenderer) Refresh() {
+	r.b.bg.Refresh()
+	r.b.text.Refresh()
+}
+
+func (r *mediaBadgeRenderer) Objects() []fyne.CanvasObject {
+	return []fyne.CanvasObject{r.b.bg, r.b.text}
+}
+
+func (r *mediaBadgeRenderer) Destroy() {}
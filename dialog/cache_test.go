@@ -1,10 +1,23 @@
 package dialog
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	imagepng "image/png"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/storage/repository"
 )
 
 func TestThumbnailManager_GenerateCacheKey(t *testing.T) {
@@ -101,3 +114,331 @@ func TestThumbnailManager_CleanupCache(t *testing.T) {
 		}
 	}
 }
+
+func TestThumbnailManager_FolderMosaic(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.png", "b.png", "notes.txt"} {
+		data := []byte("text")
+		if filepath.Ext(name) == ".png" {
+			data = testPNG(t, color.White)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+
+	tm := &ThumbnailManager{}
+	if _, err := tm.generateFolderMosaic(dir); err == nil {
+		t.Fatal("expected mosaic to be skipped while the option is disabled")
+	}
+
+	tm.SetOptions(ThumbnailOptions{FolderMosaic: true})
+	img, err := tm.generateFolderMosaic(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 128 || b.Dy() != 128 {
+		t.Fatalf("expected 128x128 mosaic, got %v", b)
+	}
+	// Two images fill the top row; the bottom row stays empty.
+	if _, _, _, a := img.At(32, 32).RGBA(); a == 0 {
+		t.Error("expected first cell to be drawn")
+	}
+	if _, _, _, a := img.At(32, 100).RGBA(); a != 0 {
+		t.Error("expected third cell to be empty")
+	}
+
+	tm.SetOptions(ThumbnailOptions{FolderMosaic: true, FolderMosaicMaxEntries: 2})
+	if _, err := tm.generateFolderMosaic(dir); err == nil {
+		t.Fatal("expected folders over the entry budget to be skipped")
+	}
+}
+
+func TestThumbnailManager_FolderCacheKeyTracksChildren(t *testing.T) {
+	dir := t.TempDir()
+	tm := &ThumbnailManager{}
+	tm.SetOptions(ThumbnailOptions{FolderMosaic: true})
+
+	key1, err := tm.generateCacheKey(dir)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "new.png"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	key2, err := tm.generateCacheKey(dir)
+	if err != nil {
+		t.Fatalf("Failed to generate key2: %v", err)
+	}
+	if key1 == key2 {
+		t.Error("Key should change when the folder listing changes")
+	}
+
+	past := time.Now().Add(-time.Hour)
+	_ = os.Chtimes(filepath.Join(dir, "new.png"), past, past)
+	key3, err := tm.generateCacheKey(dir)
+	if err != nil {
+		t.Fatalf("Failed to generate key3: %v", err)
+	}
+	if key3 == key2 {
+		t.Error("Key should change when a child's modification time changes")
+	}
+}
+
+// memRepository is a minimal read-only repository serving in-memory files.
+type memRepository struct {
+	files map[string][]byte
+	keys  map[string]string
+}
+
+type memReadCloser struct {
+	*bytes.Reader
+	uri fyne.URI
+}
+
+func (r *memReadCloser) Close() error  { return nil }
+func (r *memReadCloser) URI() fyne.URI { return r.uri }
+
+func (m *memRepository) Exists(u fyne.URI) (bool, error) {
+	_, ok := m.files[u.String()]
+	return ok, nil
+}
+
+func (m *memRepository) Reader(u fyne.URI) (fyne.URIReadCloser, error) {
+	data, ok := m.files[u.String()]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &memReadCloser{Reader: bytes.NewReader(data), uri: u}, nil
+}
+
+func (m *memRepository) CanRead(u fyne.URI) (bool, error) { return m.Exists(u) }
+func (m *memRepository) Destroy(string)                   {}
+
+type keyedMemRepository struct {
+	memRepository
+}
+
+func (m *keyedMemRepository) ThumbnailKey(u fyne.URI) (string, error) {
+	return m.keys[u.String()], nil
+}
+
+func TestThumbnailManager_NonFileURI(t *testing.T) {
+	var png bytes.Buffer
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	draw.Draw(img, img.Bounds(), &image.Uniform{color.RGBA{R: 255, A: 255}}, image.Point{}, draw.Src)
+	if err := imagepng.Encode(&png, img); err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+
+	repo := &memRepository{files: map[string][]byte{"memthumb://host/a.png": png.Bytes()}}
+	repository.Register("memthumb", repo)
+	u, err := storage.ParseURI("memthumb://host/a.png")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	tm := &ThumbnailManager{cacheDir: t.TempDir()}
+	if !tm.canThumbnail(u) {
+		t.Fatal("expected non-file image URI to be thumbnailable")
+	}
+	if thumb := tm.renderThumbnail(u); thumb == nil {
+		t.Fatal("expected thumbnail to be rendered from storage.Reader")
+	}
+	info, ok := tm.loadMediaInfo(thumbnailID(u))
+	if !ok || info.Width != 40 || info.Height != 20 {
+		t.Errorf("unexpected media info %+v", info)
+	}
+
+	key, err := tm.cacheKey(u)
+	if err != nil {
+		t.Fatalf("cache key failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tm.cacheDir, key+".jpg")); err != nil {
+		t.Errorf("expected disk cache entry: %v", err)
+	}
+
+	// A fresh manager does not read the content to look the thumbnail up;
+	// the worker finds it on disk through the content hash.
+	counting := &countingMemRepository{memRepository: *repo}
+	repository.Register("memthumb", counting)
+	fresh := &ThumbnailManager{cacheDir: tm.cacheDir}
+	if fresh.loadFromDisk(u) != nil || counting.reads != 0 {
+		t.Errorf("expected no lookup by content outside the worker, got %d reads", counting.reads)
+	}
+	if fresh.renderThumbnail(u) == nil || counting.reads != 1 {
+		t.Errorf("expected the worker to find the thumbnail with one read, got %d", counting.reads)
+	}
+	if _, err := os.Stat(filepath.Join(fresh.cacheDir, key+".jpg")); err != nil {
+		t.Errorf("expected the same disk cache entry: %v", err)
+	}
+
+	keyed := &keyedMemRepository{memRepository{files: repo.files, keys: map[string]string{"memthumbk://host/a.png": "rev1"}}}
+	keyed.files["memthumbk://host/a.png"] = png.Bytes()
+	repository.Register("memthumbk", keyed)
+	ku, _ := storage.ParseURI("memthumbk://host/a.png")
+	key1, err := tm.cacheKey(ku)
+	if err != nil {
+		t.Fatalf("keyed cache key failed: %v", err)
+	}
+	keyed.keys["memthumbk://host/a.png"] = "rev2"
+	if key2, _ := tm.cacheKey(ku); key2 == key1 {
+		t.Error("expected repository key change to change the cache key")
+	}
+}
+
+func TestThumbnailManager_CleanupCacheStoryboards(t *testing.T) {
+	tmpDir := t.TempDir()
+	tm := &ThumbnailManager{cacheDir: tmpDir}
+
+	oldFiles := MaxCacheFiles
+	MaxCacheFiles = 2
+	defer func() { MaxCacheFiles = oldFiles }()
+
+	for i, name := range []string{"old_sb.jpg", "old.jpg", "new.jpg"} {
+		path := filepath.Join(tmpDir, name)
+		_ = os.WriteFile(path, []byte("fake image data"), 0644)
+		mtime := time.Now().Add(time.Duration(i-100) * time.Minute)
+		_ = os.Chtimes(path, mtime, mtime)
+	}
+	// The storyboard is newer than its thumbnail but goes with it.
+	now := time.Now()
+	_ = os.Chtimes(filepath.Join(tmpDir, "old_sb.jpg"), now, now)
+
+	tm.cleanupCache()
+
+	files, _ := os.ReadDir(tmpDir)
+	if len(files) != 1 || files[0].Name() != "new.jpg" {
+		var names []string
+		for _, f := range files {
+			names = append(names, f.Name())
+		}
+		t.Errorf("cache holds %v, want only new.jpg", names)
+	}
+}
+
+func TestStoryboardCache_Bounded(t *testing.T) {
+	var c storyboardCache
+	for i := range maxStoryboards + 5 {
+		c.add(fmt.Sprintf("/v/%d.mp4", i), &storyboard{})
+		if i == 0 {
+			continue
+		}
+		// Keep the first one in use.
+		c.get("/v/0.mp4")
+	}
+	if c.order.Len() != maxStoryboards || len(c.items) != maxStoryboards {
+		t.Errorf("cache holds %d/%d storyboards, want %d", c.order.Len(), len(c.items), maxStoryboards)
+	}
+	if c.get("/v/0.mp4") == nil {
+		t.Error("expected the recently used storyboard to be kept")
+	}
+	if c.get("/v/1.mp4") != nil {
+		t.Error("expected the least recently used storyboard to be evicted")
+	}
+}
+
+func TestThumbnailManager_LoadFolderMosaic(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.png"), testPNG(t, color.White), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	tm := &ThumbnailManager{cacheDir: t.TempDir()}
+	tm.reqCond = sync.NewCond(&tm.reqLock)
+	tm.SetOptions(ThumbnailOptions{FolderMosaic: true})
+	go tm.worker()
+
+	load := func() image.Image {
+		t.Helper()
+		got := make(chan *canvas.Image, 1)
+		tm.Load(storage.NewFileURI(dir), func(img *canvas.Image) { got <- img })
+		select {
+		case img := <-got:
+			return img.Image
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the mosaic")
+			return nil
+		}
+	}
+
+	first := load()
+	if _, _, _, a := first.At(96, 32).RGBA(); a != 0 {
+		t.Fatal("expected the second cell to be empty with one image")
+	}
+	if tm.LoadMemoryOnly(thumbnailID(storage.NewFileURI(filepath.Join(dir, "a.png")))) == nil {
+		t.Error("expected the child to be thumbnailed and cached like any other")
+	}
+	if img := tm.LoadMemoryOnly(thumbnailID(storage.NewFileURI(dir))); img == nil || img.Image != first {
+		t.Error("expected the mosaic in memory under the folder's id for rebinding")
+	}
+
+	// A changed folder gets a new mosaic rather than the one held in memory.
+	if err := os.WriteFile(filepath.Join(dir, "b.png"), testPNG(t, color.White), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if _, _, _, a := load().At(96, 32).RGBA(); a == 0 {
+		t.Error("expected the mosaic to be rebuilt with the new image")
+	}
+}
+
+type countingMemRepository struct {
+	memRepository
+	reads int
+}
+
+func (m *countingMemRepository) Reader(u fyne.URI) (fyne.URIReadCloser, error) {
+	m.reads++
+	return m.memRepository.Reader(u)
+}
+
+func TestThumbnailManager_LocalCopyReadsStreamOnce(t *testing.T) {
+	data := bytes.Repeat([]byte("frame"), 10000)
+	repo := &countingMemRepository{memRepository: memRepository{files: map[string][]byte{"memcopy://host/clip.mp4": data}}}
+	repository.Register("memcopy", repo)
+	u, _ := storage.ParseURI("memcopy://host/clip.mp4")
+
+	tm := &ThumbnailManager{}
+	src, release, err := tm.localCopy(u)
+	if err != nil {
+		t.Fatalf("copy failed: %v", err)
+	}
+	if src.Scheme() != "file" || src.Extension() != ".mp4" {
+		t.Errorf("copy is at %s, want a local file keeping the extension", src)
+	}
+	if got, _ := os.ReadFile(src.Path()); !bytes.Equal(got, data) {
+		t.Error("expected the copy to hold the streamed content")
+	}
+
+	// The copy also provides the content key, so no second read is needed.
+	key, err := tm.cacheKey(u)
+	if err != nil || repo.reads != 1 {
+		t.Errorf("key %q, err %v after %d reads, want one read", key, err, repo.reads)
+	}
+	if fresh, _ := (&ThumbnailManager{}).cacheKey(u); fresh != key {
+		t.Error("expected the same key as hashing the stream directly")
+	}
+
+	release()
+	if _, err := os.Stat(src.Path()); !os.IsNotExist(err) {
+		t.Error("expected release to remove the copy")
+	}
+}
+
+func TestThumbnailManager_LoadQueuesRemoteWithoutHashing(t *testing.T) {
+	repo := &countingMemRepository{memRepository: memRepository{files: map[string][]byte{"memqueue://host/a.png": testPNG(t, color.White)}}}
+	repository.Register("memqueue", repo)
+	u, _ := storage.ParseURI("memqueue://host/a.png")
+
+	tm := &ThumbnailManager{cacheDir: t.TempDir()}
+	tm.reqCond = sync.NewCond(&tm.reqLock)
+	tm.Load(u, func(*canvas.Image) {})
+
+	// Only the signature is read; the content is left to the workers.
+	tm.reqLock.Lock()
+	queued := len(tm.requests)
+	tm.reqLock.Unlock()
+	if queued != 1 || repo.reads != 1 {
+		t.Errorf("queued %d requests after %d reads, want 1 after the signature read", queued, repo.reads)
+	}
+}
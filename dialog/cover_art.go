@@ -0,0 +1,404 @@
+package dialog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var errNoCoverArt = errors.New("no embedded cover art")
+
+// coverFileNames are the sidecar images used when a track has no embedded art.
+var coverFileNames = []string{"cover.jpg", "cover.jpeg", "cover.png", "folder.jpg", "folder.jpeg", "folder.png"}
+
+// maxCoverArtSize bounds the picture data we are willing to read from a tag.
+const maxCoverArtSize = 16 * 1024 * 1024
+
+// audioFormatNames are the badge captions for the audio types we read cover art from.
+var audioFormatNames = map[string]string{
+	"audio/mpeg": "MP3",
+	"audio/flac": "FLAC",
+	"audio/mp4":  "M4A",
+}
+
+// loadCoverArt returns the picture embedded in an audio file, falling back to a
+// cover or folder image in the same directory.
+func loadCoverArt(path string) (image.Image, error) {
+	data, err := readEmbeddedCoverArt(path)
+	if err == nil {
+		img, _, decodeErr := image.Decode(bytes.NewReader(data))
+		if decodeErr == nil {
+			return img, nil
+		}
+	}
+
+	if cover := findFolderCover(filepath.Dir(path)); cover != "" {
+		return loadImage(cover)
+	}
+	if err == nil {
+		err = errNoCoverArt
+	}
+	return nil, err
+}
+
+func readEmbeddedCoverArt(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	head := make([]byte, sniffLen)
+	n, _ := f.ReadAt(head, 0)
+	switch combineFileType(extensionFileType(path), sniffFileType(head[:n], strings.ToLower(filepath.Ext(path)))).mime {
+	case "audio/mpeg":
+		return readID3Picture(f)
+	case "audio/flac":
+		return readFLACPicture(f)
+	case "audio/mp4":
+		return readMP4Cover(f)
+	}
+	return nil, errNoCoverArt
+}
+
+func findFolderCover(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, want := range coverFileNames {
+		for _, e := range entries {
+			if !e.IsDir() && strings.EqualFold(e.Name(), want) {
+				return filepath.Join(dir, e.Name())
+			}
+		}
+	}
+	return ""
+}
+
+// readID3Picture extracts an APIC (v2.3/v2.4) or PIC (v2.2) frame from an ID3v2 tag,
+// preferring the front cover when several pictures are present.
+func readID3Picture(r io.Reader) ([]byte, error) {
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if string(header[:3]) != "ID3" {
+		return nil, errNoCoverArt
+	}
+	version := header[3]
+	flags := header[5]
+	size := syncsafeUint32(header[6:10])
+	if size > maxCoverArtSize {
+		return nil, errNoCoverArt
+	}
+
+	tag := make([]byte, size)
+	if _, err := io.ReadFull(r, tag); err != nil {
+		return nil, err
+	}
+	if flags&0x80 != 0 && version < 4 {
+		tag = removeUnsynchronisation(tag)
+	}
+	if flags&0x40 != 0 && version >= 3 && len(tag) >= 4 {
+		// Skip the extended header. Its v2.4 size is syncsafe and includes
+		// the size field itself.
+		ext := int(binary.BigEndian.Uint32(tag[:4])) + 4
+		if version == 4 {
+			ext = int(syncsafeUint32(tag[:4]))
+		}
+		if ext < 4 || ext > len(tag) {
+			return nil, errNoCoverArt
+		}
+		tag = tag[ext:]
+	}
+
+	idLen, headerLen := 4, 10
+	if version == 2 {
+		idLen, headerLen = 3, 6
+	}
+
+	var fallback []byte
+	for len(tag) >= headerLen && tag[0] != 0 {
+		id := string(tag[:idLen])
+		var frameSize int
+		switch version {
+		case 2:
+			frameSize = int(tag[3])<<16 | int(tag[4])<<8 | int(tag[5])
+		case 4:
+			frameSize = int(syncsafeUint32(tag[4:8]))
+		default:
+			frameSize = int(binary.BigEndian.Uint32(tag[4:8]))
+		}
+		if frameSize <= 0 || headerLen+frameSize > len(tag) {
+			break
+		}
+		body := tag[headerLen : headerLen+frameSize]
+		var frameFlags byte
+		if version == 4 {
+			frameFlags = tag[9]
+		}
+		tag = tag[headerLen+frameSize:]
+
+		if id != "APIC" && id != "PIC" {
+			continue
+		}
+		if version == 4 {
+			// Compressed and encrypted frames are not supported.
+			if frameFlags&0x0c != 0 {
+				continue
+			}
+			// A data length indicator precedes the frame data.
+			if frameFlags&0x01 != 0 {
+				if len(body) < 4 {
+					continue
+				}
+				body = body[4:]
+			}
+			// v2.4 unsynchronises frame by frame; the tag flag marks all of them.
+			if frameFlags&0x02 != 0 || flags&0x80 != 0 {
+				body = removeUnsynchronisation(body)
+			}
+		}
+		picType, data, ok := parseID3PictureFrame(body, id == "PIC")
+		if !ok {
+			continue
+		}
+		if picType == 3 {
+			return data, nil
+		}
+		if fallback == nil {
+			fallback = data
+		}
+	}
+	if fallback == nil {
+		return nil, errNoCoverArt
+	}
+	return fallback, nil
+}
+
+func parseID3PictureFrame(body []byte, legacy bool) (picType byte, data []byte, ok bool) {
+	if len(body) < 2 {
+		return 0, nil, false
+	}
+	encoding := body[0]
+	rest := body[1:]
+
+	if legacy {
+		// 3 character image format instead of a MIME type.
+		if len(rest) < 3 {
+			return 0, nil, false
+		}
+		rest = rest[3:]
+	} else {
+		end := bytes.IndexByte(rest, 0)
+		if end < 0 {
+			return 0, nil, false
+		}
+		rest = rest[end+1:]
+	}
+
+	if len(rest) < 1 {
+		return 0, nil, false
+	}
+	picType = rest[0]
+	rest = rest[1:]
+
+	// The description is terminated by a single or double NUL depending on encoding.
+	if encoding == 1 || encoding == 2 {
+		for i := 0; i+1 < len(rest); i += 2 {
+			if rest[i] == 0 && rest[i+1] == 0 {
+				return picType, rest[i+2:], true
+			}
+		}
+		return 0, nil, false
+	}
+	end := bytes.IndexByte(rest, 0)
+	if end < 0 {
+		return 0, nil, false
+	}
+	return picType, rest[end+1:], true
+}
+
+func syncsafeUint32(b []byte) uint32 {
+	return uint32(b[0]&0x7f)<<21 | uint32(b[1]&0x7f)<<14 | uint32(b[2]&0x7f)<<7 | uint32(b[3]&0x7f)
+}
+
+func removeUnsynchronisation(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		out = append(out, b[i])
+		if b[i] == 0xff && i+1 < len(b) && b[i+1] == 0 {
+			i++
+		}
+	}
+	return out
+}
+
+// readFLACPicture returns the front cover, or first picture, from the FLAC metadata blocks.
+func readFLACPicture(r io.Reader) ([]byte, error) {
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, err
+	}
+	if string(magic) != "fLaC" {
+		return nil, errNoCoverArt
+	}
+
+	var fallback []byte
+	header := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			break
+		}
+		last := header[0]&0x80 != 0
+		blockType := header[0] & 0x7f
+		length := int(header[1])<<16 | int(header[2])<<8 | int(header[3])
+
+		if blockType != 6 {
+			if _, err := io.CopyN(io.Discard, r, int64(length)); err != nil {
+				break
+			}
+		} else {
+			block := make([]byte, length)
+			if _, err := io.ReadFull(r, block); err != nil {
+				break
+			}
+			picType, data, ok := parseFLACPictureBlock(block)
+			if ok && picType == 3 {
+				return data, nil
+			}
+			if ok && fallback == nil {
+				fallback = data
+			}
+		}
+		if last {
+			break
+		}
+	}
+	if fallback == nil {
+		return nil, errNoCoverArt
+	}
+	return fallback, nil
+}
+
+func parseFLACPictureBlock(b []byte) (picType uint32, data []byte, ok bool) {
+	next := func(n int) ([]byte, bool) {
+		if n < 0 || len(b) < n {
+			return nil, false
+		}
+		v := b[:n]
+		b = b[n:]
+		return v, true
+	}
+	u32 := func() (uint32, bool) {
+		v, ok := next(4)
+		if !ok {
+			return 0, false
+		}
+		return binary.BigEndian.Uint32(v), true
+	}
+
+	if picType, ok = u32(); !ok {
+		return 0, nil, false
+	}
+	for range 2 { // MIME type, then description
+		n, ok := u32()
+		if !ok {
+			return 0, nil, false
+		}
+		if _, ok := next(int(n)); !ok {
+			return 0, nil, false
+		}
+	}
+	if _, ok := next(16); !ok { // width, height, depth, colours
+		return 0, nil, false
+	}
+	n, ok := u32()
+	if !ok {
+		return 0, nil, false
+	}
+	data, ok = next(int(n))
+	return picType, data, ok
+}
+
+// readMP4Cover walks moov/udta/meta/ilst/covr/data to find iTunes style cover art.
+func readMP4Cover(r io.ReadSeeker) ([]byte, error) {
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	path := []string{"moov", "udta", "meta", "ilst", "covr", "data"}
+	start := int64(0)
+	for depth, name := range path {
+		atomStart, atomSize, found, err := findMP4Atom(r, start, end, name)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, errNoCoverArt
+		}
+		start, end = atomStart, atomStart+atomSize
+		if name == "meta" {
+			// meta is a full box: skip version and flags.
+			start += 4
+		}
+		if depth == len(path)-1 {
+			// data: 4 byte type indicator and 4 byte locale precede the payload.
+			start += 8
+		}
+	}
+
+	size := end - start
+	if size <= 0 || size > maxCoverArtSize {
+		return nil, errNoCoverArt
+	}
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// findMP4Atom scans sibling atoms between start and end for the named one and
+// returns the offset and size of its payload.
+func findMP4Atom(r io.ReadSeeker, start, end int64, name string) (int64, int64, bool, error) {
+	header := make([]byte, 8)
+	for pos := start; pos+8 <= end; {
+		if _, err := r.Seek(pos, io.SeekStart); err != nil {
+			return 0, 0, false, err
+		}
+		if _, err := io.ReadFull(r, header); err != nil {
+			return 0, 0, false, err
+		}
+		size := int64(binary.BigEndian.Uint32(header[:4]))
+		headerLen := int64(8)
+		switch size {
+		case 0:
+			size = end - pos
+		case 1:
+			ext := make([]byte, 8)
+			if _, err := io.ReadFull(r, ext); err != nil {
+				return 0, 0, false, err
+			}
+			size = int64(binary.BigEndian.Uint64(ext))
+			headerLen = 16
+		}
+		if size < headerLen || pos+size > end {
+			return 0, 0, false, nil
+		}
+		if string(header[4:8]) == name {
+			return pos + headerLen, size - headerLen, true, nil
+		}
+		pos += size
+	}
+	return 0, 0, false, nil
+}
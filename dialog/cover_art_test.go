@@ -0,0 +1,185 @@
+package dialog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testPNG(t *testing.T, c color.Color) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png encode failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func id3v23Tag(frames ...[]byte) []byte {
+	var body []byte
+	for _, f := range frames {
+		body = append(body, f...)
+	}
+	size := len(body)
+	header := []byte{'I', 'D', '3', 3, 0, 0,
+		byte(size >> 21 & 0x7f), byte(size >> 14 & 0x7f), byte(size >> 7 & 0x7f), byte(size & 0x7f)}
+	return append(header, body...)
+}
+
+func id3v23Frame(id string, body []byte) []byte {
+	frame := []byte(id)
+	frame = binary.BigEndian.AppendUint32(frame, uint32(len(body)))
+	frame = append(frame, 0, 0)
+	return append(frame, body...)
+}
+
+func apicBody(picType byte, data []byte) []byte {
+	body := []byte{0}
+	body = append(body, "image/png"...)
+	body = append(body, 0, picType)
+	body = append(body, "desc"...)
+	body = append(body, 0)
+	return append(body, data...)
+}
+
+func TestReadID3Picture_PrefersFrontCover(t *testing.T) {
+	back := testPNG(t, color.White)
+	front := testPNG(t, color.Black)
+	tag := id3v23Tag(
+		id3v23Frame("TIT2", []byte{0, 'x'}),
+		id3v23Frame("APIC", apicBody(4, back)),
+		id3v23Frame("APIC", apicBody(3, front)),
+	)
+	tag = append(tag, 0xff, 0xfb) // start of audio
+
+	got, err := readID3Picture(bytes.NewReader(tag))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(got, front) {
+		t.Fatal("expected front cover picture data")
+	}
+
+	if _, err := readID3Picture(bytes.NewReader([]byte("not a tag at all"))); err == nil {
+		t.Fatal("expected error without ID3 header")
+	}
+}
+
+func TestReadID3Picture_V24(t *testing.T) {
+	// Picture data with false syncs, unsynchronised in the frame.
+	cover := append(testPNG(t, color.Black), 0xff, 0x00, 0xff, 0xe0)
+	unsynced := bytes.ReplaceAll(apicBody(3, cover), []byte{0xff}, []byte{0xff, 0x00})
+
+	syncsafe := func(n int) []byte {
+		return []byte{byte(n >> 21 & 0x7f), byte(n >> 14 & 0x7f), byte(n >> 7 & 0x7f), byte(n & 0x7f)}
+	}
+	frame := func(id string, flags byte, body []byte) []byte {
+		f := append([]byte(id), syncsafe(len(body))...)
+		f = append(f, 0, flags)
+		return append(f, body...)
+	}
+	// An extended header of 6 bytes: its size, one flag byte and no flags.
+	body := append(syncsafe(6), 1, 0)
+	body = append(body, frame("TIT2", 0, []byte{3, 'x'})...)
+	body = append(body, frame("APIC", 0x02|0x01, append(syncsafe(len(apicBody(3, cover))), unsynced...))...)
+	tag := append([]byte{'I', 'D', '3', 4, 0, 0x40}, syncsafe(len(body))...)
+	tag = append(tag, body...)
+
+	got, err := readID3Picture(bytes.NewReader(tag))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(got, cover) {
+		t.Fatal("expected the resynchronised front cover")
+	}
+}
+
+func TestReadFLACPicture(t *testing.T) {
+	pic := testPNG(t, color.Black)
+
+	block := binary.BigEndian.AppendUint32(nil, 3)
+	block = binary.BigEndian.AppendUint32(block, uint32(len("image/png")))
+	block = append(block, "image/png"...)
+	block = binary.BigEndian.AppendUint32(block, 0)
+	block = append(block, make([]byte, 16)...)
+	block = binary.BigEndian.AppendUint32(block, uint32(len(pic)))
+	block = append(block, pic...)
+
+	var buf bytes.Buffer
+	buf.WriteString("fLaC")
+	buf.Write([]byte{0, 0, 0, 34}) // STREAMINFO
+	buf.Write(make([]byte, 34))
+	buf.Write([]byte{0x80 | 6, byte(len(block) >> 16), byte(len(block) >> 8), byte(len(block))})
+	buf.Write(block)
+
+	got, err := readFLACPicture(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(got, pic) {
+		t.Fatal("expected picture block data")
+	}
+}
+
+func mp4Atom(name string, payload ...[]byte) []byte {
+	var body []byte
+	for _, p := range payload {
+		body = append(body, p...)
+	}
+	atom := binary.BigEndian.AppendUint32(nil, uint32(len(body)+8))
+	atom = append(atom, name...)
+	return append(atom, body...)
+}
+
+func TestReadMP4Cover(t *testing.T) {
+	pic := testPNG(t, color.Black)
+	data := mp4Atom("data", []byte{0, 0, 0, 14, 0, 0, 0, 0}, pic)
+	meta := mp4Atom("meta", []byte{0, 0, 0, 0}, mp4Atom("hdlr", make([]byte, 25)), mp4Atom("ilst", mp4Atom("covr", data)))
+	file := append(mp4Atom("ftyp", []byte("M4A ")), mp4Atom("moov", mp4Atom("mvhd", make([]byte, 100)), mp4Atom("udta", meta))...)
+
+	got, err := readMP4Cover(bytes.NewReader(file))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(got, pic) {
+		t.Fatal("expected covr data payload")
+	}
+
+	if _, err := readMP4Cover(bytes.NewReader(mp4Atom("ftyp", []byte("M4A ")))); err == nil {
+		t.Fatal("expected error when no cover atom exists")
+	}
+}
+
+func TestLoadCoverArt_FallsBackToFolderImage(t *testing.T) {
+	dir := t.TempDir()
+	track := filepath.Join(dir, "track.mp3")
+	if err := os.WriteFile(track, []byte{0xff, 0xfb, 0x90, 0x00}, 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	if _, err := loadCoverArt(track); err == nil {
+		t.Fatal("expected error without embedded art or folder image")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "Folder.PNG"), testPNG(t, color.White), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	img, err := loadCoverArt(track)
+	if err != nil {
+		t.Fatalf("expected folder image fallback, got %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 4 || b.Dy() != 2 {
+		t.Fatalf("unexpected fallback image size %v", b)
+	}
+}
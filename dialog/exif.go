@@ -0,0 +1,281 @@
+package dialog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var errNoEXIF = errors.New("no EXIF data")
+
+// maxEXIFSize bounds the metadata block we are willing to read.
+const maxEXIFSize = 256 * 1024
+
+// EXIF tags we read. IFD0 holds the camera, the Exif sub-IFD the exposure.
+const (
+	exifTagMake             = 0x010f
+	exifTagModel            = 0x0110
+	exifTagDateTime         = 0x0132
+	exifTagExifIFD          = 0x8769
+	exifTagGPSIFD           = 0x8825
+	exifTagExposureTime     = 0x829a
+	exifTagFNumber          = 0x829d
+	exifTagISO              = 0x8827
+	exifTagDateTimeOriginal = 0x9003
+	exifTagFocalLength      = 0x920a
+	exifTagLensModel        = 0xa434
+	gpsTagLatitude          = 0x0002
+)
+
+// readEXIF extracts camera metadata from a JPEG, PNG or WebP stream into info.
+func readEXIF(r io.Reader, info *mediaInfo) error {
+	head := make([]byte, 12)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return err
+	}
+
+	var tiff []byte
+	var err error
+	switch {
+	case bytes.HasPrefix(head, []byte{0xff, 0xd8}):
+		tiff, err = jpegEXIF(io.MultiReader(bytes.NewReader(head[2:]), r))
+	case bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1a\n")):
+		tiff, err = pngEXIF(io.MultiReader(bytes.NewReader(head[8:]), r))
+	case string(head[:4]) == "RIFF" && string(head[8:12]) == "WEBP":
+		tiff, err = webpEXIF(r)
+	default:
+		return errNoEXIF
+	}
+	if err != nil {
+		return err
+	}
+	return parseTIFFMetadata(tiff, info)
+}
+
+// jpegEXIF walks the JPEG segments up to the image data looking for an APP1 Exif block.
+func jpegEXIF(r io.Reader) ([]byte, error) {
+	header := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, err
+		}
+		if header[0] != 0xff {
+			return nil, errNoEXIF
+		}
+		marker := header[1]
+		length := int(binary.BigEndian.Uint16(header[2:])) - 2
+		if marker == 0xda || marker == 0xd9 || length < 0 {
+			return nil, errNoEXIF
+		}
+		if marker != 0xe1 || length > maxEXIFSize {
+			if _, err := io.CopyN(io.Discard, r, int64(length)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		data := make([]byte, length)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		if bytes.HasPrefix(data, []byte("Exif\x00\x00")) {
+			return data[6:], nil
+		}
+	}
+}
+
+// pngEXIF looks for an eXIf chunk before the image data.
+func pngEXIF(r io.Reader) ([]byte, error) {
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, err
+		}
+		length := int64(binary.BigEndian.Uint32(header[:4]))
+		switch string(header[4:]) {
+		case "eXIf":
+			if length > maxEXIFSize {
+				return nil, errNoEXIF
+			}
+			data := make([]byte, length)
+			_, err := io.ReadFull(r, data)
+			return data, err
+		case "IDAT", "IEND":
+			return nil, errNoEXIF
+		}
+		// Skip the chunk data and CRC.
+		if _, err := io.CopyN(io.Discard, r, length+4); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// webpEXIF looks for the EXIF chunk of an extended WebP file.
+func webpEXIF(r io.Reader) ([]byte, error) {
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, err
+		}
+		length := int64(binary.LittleEndian.Uint32(header[4:]))
+		if string(header[:4]) == "EXIF" {
+			if length > maxEXIFSize {
+				return nil, errNoEXIF
+			}
+			data := make([]byte, length)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return nil, err
+			}
+			return bytes.TrimPrefix(data, []byte("Exif\x00\x00")), nil
+		}
+		// Chunks are padded to an even size.
+		if _, err := io.CopyN(io.Discard, r, length+length%2); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// tiffReader resolves IFD entries in a TIFF structured EXIF block.
+type tiffReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+type tiffEntry struct {
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+var tiffTypeSizes = map[uint16]uint32{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 7: 1, 9: 4, 10: 8}
+
+func (t *tiffReader) ifd(offset uint32) (map[uint16]tiffEntry, error) {
+	if uint64(offset)+2 > uint64(len(t.data)) {
+		return nil, errNoEXIF
+	}
+	n := int(t.order.Uint16(t.data[offset:]))
+	entries := make(map[uint16]tiffEntry, n)
+	for i := range n {
+		pos := int(offset) + 2 + i*12
+		if pos+12 > len(t.data) {
+			break
+		}
+		e := t.data[pos : pos+12]
+		typ := t.order.Uint16(e[2:])
+		count := t.order.Uint32(e[4:])
+		size, ok := tiffTypeSizes[typ]
+		if !ok || count > maxEXIFSize {
+			continue
+		}
+		total := size * count
+		value := e[8:12]
+		if total > 4 {
+			off := t.order.Uint32(e[8:])
+			if uint64(off)+uint64(total) > uint64(len(t.data)) {
+				continue
+			}
+			value = t.data[off : off+total]
+		}
+		entries[t.order.Uint16(e)] = tiffEntry{typ: typ, count: count, value: value[:min(total, uint32(len(value)))]}
+	}
+	return entries, nil
+}
+
+func (t *tiffReader) str(e tiffEntry) string {
+	if e.typ != 2 {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(e.value), "\x00"))
+}
+
+func (t *tiffReader) uint(e tiffEntry) (uint32, bool) {
+	switch {
+	case e.typ == 3 && len(e.value) >= 2:
+		return uint32(t.order.Uint16(e.value)), true
+	case e.typ == 4 && len(e.value) >= 4:
+		return t.order.Uint32(e.value), true
+	}
+	return 0, false
+}
+
+func (t *tiffReader) rational(e tiffEntry) (num, den uint32, ok bool) {
+	if (e.typ != 5 && e.typ != 10) || len(e.value) < 8 {
+		return 0, 0, false
+	}
+	num, den = t.order.Uint32(e.value), t.order.Uint32(e.value[4:])
+	return num, den, den != 0
+}
+
+// parseTIFFMetadata reads the camera, lens, exposure, capture date and GPS
+// presence from an EXIF TIFF block.
+func parseTIFFMetadata(data []byte, info *mediaInfo) error {
+	if len(data) < 8 {
+		return errNoEXIF
+	}
+	t := &tiffReader{data: data}
+	switch string(data[:2]) {
+	case "II":
+		t.order = binary.LittleEndian
+	case "MM":
+		t.order = binary.BigEndian
+	default:
+		return errNoEXIF
+	}
+	if t.order.Uint16(data[2:]) != 42 {
+		return errNoEXIF
+	}
+
+	ifd0, err := t.ifd(t.order.Uint32(data[4:]))
+	if err != nil {
+		return err
+	}
+	maker := t.str(ifd0[exifTagMake])
+	model := t.str(ifd0[exifTagModel])
+	info.Camera = model
+	if maker != "" && !strings.HasPrefix(strings.ToLower(model), strings.ToLower(maker)) {
+		info.Camera = strings.TrimSpace(maker + " " + model)
+	}
+	taken := t.str(ifd0[exifTagDateTime])
+
+	if off, ok := t.uint(ifd0[exifTagExifIFD]); ok {
+		if exif, err := t.ifd(off); err == nil {
+			if num, den, ok := t.rational(exif[exifTagExposureTime]); ok && num > 0 {
+				info.Exposure = formatExposure(num, den)
+			}
+			if num, den, ok := t.rational(exif[exifTagFNumber]); ok {
+				info.Aperture = float64(num) / float64(den)
+			}
+			if iso, ok := t.uint(exif[exifTagISO]); ok {
+				info.ISO = int(iso)
+			}
+			if num, den, ok := t.rational(exif[exifTagFocalLength]); ok {
+				info.FocalLength = float64(num) / float64(den)
+			}
+			info.Lens = t.str(exif[exifTagLensModel])
+			if original := t.str(exif[exifTagDateTimeOriginal]); original != "" {
+				taken = original
+			}
+		}
+	}
+	if ts, err := time.ParseInLocation("2006:01:02 15:04:05", taken, time.Local); err == nil {
+		info.Taken = ts
+	}
+
+	if off, ok := t.uint(ifd0[exifTagGPSIFD]); ok {
+		if gps, err := t.ifd(off); err == nil {
+			_, info.GPS = gps[gpsTagLatitude]
+		}
+	}
+	return nil
+}
+
+// formatExposure renders an exposure time as "1/250 s" or "2.5 s".
+func formatExposure(num, den uint32) string {
+	if num < den {
+		return fmt.Sprintf("1/%d s", (den+num/2)/num)
+	}
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.1f", float64(num)/float64(den)), "0"), ".") + " s"
+}
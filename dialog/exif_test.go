@@ -0,0 +1,140 @@
+package dialog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/color"
+	"testing"
+	"time"
+)
+
+type testTIFFEntry struct {
+	tag, typ uint16
+	count    uint32
+	data     []byte
+}
+
+func asciiEntry(tag uint16, s string) testTIFFEntry {
+	return testTIFFEntry{tag: tag, typ: 2, count: uint32(len(s) + 1), data: append([]byte(s), 0)}
+}
+
+func rationalEntry(tag uint16, values ...uint32) testTIFFEntry {
+	var data []byte
+	for _, v := range values {
+		data = binary.LittleEndian.AppendUint32(data, v)
+	}
+	return testTIFFEntry{tag: tag, typ: 5, count: uint32(len(values) / 2), data: data}
+}
+
+func shortEntry(tag, v uint16) testTIFFEntry {
+	return testTIFFEntry{tag: tag, typ: 3, count: 1, data: binary.LittleEndian.AppendUint16(nil, v)}
+}
+
+func longEntry(tag uint16, v uint32) testTIFFEntry {
+	return testTIFFEntry{tag: tag, typ: 4, count: 1, data: binary.LittleEndian.AppendUint32(nil, v)}
+}
+
+// appendTestIFD writes a little endian IFD followed by its out of line values
+// and returns the IFD offset.
+func appendTestIFD(buf *[]byte, entries []testTIFFEntry) uint32 {
+	offset := uint32(len(*buf))
+	dataOff := offset + 2 + uint32(len(entries))*12 + 4
+	var data []byte
+	out := binary.LittleEndian.AppendUint16(*buf, uint16(len(entries)))
+	for _, e := range entries {
+		out = binary.LittleEndian.AppendUint16(out, e.tag)
+		out = binary.LittleEndian.AppendUint16(out, e.typ)
+		out = binary.LittleEndian.AppendUint32(out, e.count)
+		if len(e.data) > 4 {
+			out = binary.LittleEndian.AppendUint32(out, dataOff+uint32(len(data)))
+			data = append(data, e.data...)
+		} else {
+			value := make([]byte, 4)
+			copy(value, e.data)
+			out = append(out, value...)
+		}
+	}
+	out = append(out, 0, 0, 0, 0)
+	*buf = append(out, data...)
+	return offset
+}
+
+func testEXIFBlock() []byte {
+	buf := []byte{'I', 'I', 42, 0, 0, 0, 0, 0}
+	exif := appendTestIFD(&buf, []testTIFFEntry{
+		rationalEntry(exifTagExposureTime, 1, 250),
+		rationalEntry(exifTagFNumber, 28, 10),
+		shortEntry(exifTagISO, 400),
+		asciiEntry(exifTagDateTimeOriginal, "2025:06:14 09:30:00"),
+		rationalEntry(exifTagFocalLength, 23, 1),
+		asciiEntry(exifTagLensModel, "XF23mmF2"),
+	})
+	gps := appendTestIFD(&buf, []testTIFFEntry{
+		rationalEntry(gpsTagLatitude, 51, 1, 30, 1, 0, 1),
+	})
+	ifd0 := appendTestIFD(&buf, []testTIFFEntry{
+		asciiEntry(exifTagMake, "FUJIFILM"),
+		asciiEntry(exifTagModel, "X100V"),
+		asciiEntry(exifTagDateTime, "2025:07:01 10:00:00"),
+		longEntry(exifTagExifIFD, exif),
+		longEntry(exifTagGPSIFD, gps),
+	})
+	binary.LittleEndian.PutUint32(buf[4:], ifd0)
+	return buf
+}
+
+func TestReadEXIF_JPEG(t *testing.T) {
+	app1 := append([]byte("Exif\x00\x00"), testEXIFBlock()...)
+	var jpeg []byte
+	jpeg = append(jpeg, 0xff, 0xd8)
+	// An APP0 segment before the Exif block must be skipped.
+	jpeg = append(jpeg, 0xff, 0xe0, 0, 6, 'J', 'F', 'I', 'F')
+	jpeg = append(jpeg, 0xff, 0xe1)
+	jpeg = binary.BigEndian.AppendUint16(jpeg, uint16(len(app1)+2))
+	jpeg = append(jpeg, app1...)
+	jpeg = append(jpeg, 0xff, 0xda, 0, 2)
+
+	var info mediaInfo
+	if err := readEXIF(bytes.NewReader(jpeg), &info); err != nil {
+		t.Fatalf("readEXIF failed: %v", err)
+	}
+	if info.Camera != "FUJIFILM X100V" || info.Lens != "XF23mmF2" {
+		t.Errorf("camera/lens = %q/%q", info.Camera, info.Lens)
+	}
+	if info.Exposure != "1/250 s" || info.Aperture != 2.8 || info.ISO != 400 || info.FocalLength != 23 {
+		t.Errorf("exposure = %q f/%v ISO %d %vmm", info.Exposure, info.Aperture, info.ISO, info.FocalLength)
+	}
+	if want := time.Date(2025, 6, 14, 9, 30, 0, 0, time.Local); !info.Taken.Equal(want) {
+		t.Errorf("taken = %v, want the DateTimeOriginal %v", info.Taken, want)
+	}
+	if !info.GPS {
+		t.Error("expected GPS presence")
+	}
+	if got := info.exposureSummary(); got != "f/2.8 · 1/250 s · ISO 400 · 23 mm" {
+		t.Errorf("exposureSummary = %q", got)
+	}
+}
+
+func TestReadEXIF_PNGWithoutMetadata(t *testing.T) {
+	var info mediaInfo
+	if err := readEXIF(bytes.NewReader(testPNG(t, color.Black)), &info); err != errNoEXIF {
+		t.Fatalf("expected errNoEXIF, got %v", err)
+	}
+	if info.Camera != "" || info.GPS {
+		t.Errorf("unexpected metadata %+v", info)
+	}
+}
+
+func TestFormatExposure(t *testing.T) {
+	cases := map[[2]uint32]string{
+		{1, 250}:  "1/250 s",
+		{10, 600}: "1/60 s",
+		{5, 2}:    "2.5 s",
+		{30, 1}:   "30 s",
+	}
+	for in, want := range cases {
+		if got := formatExposure(in[0], in[1]); got != want {
+			t.Errorf("formatExposure(%d, %d) = %q, want %q", in[0], in[1], got, want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package dialog
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// FileBrowser is the browsing part of the file dialogs as a widget: the
+// sidebar, breadcrumb, controls, file list and preview, without a title or
+// Open and Cancel buttons. It can be placed anywhere in an app's layout.
+// The dialogs are not built on FileBrowser; a browser is an open dialog that
+// is never shown, with the browsing view as its content.
+//
+// Files and folders can both be selected. The keyboard works as in the
+// dialogs while the browser is visible in its window: Enter opens the
+// selection, Space shows Quick Look and typing searches.
+type FileBrowser struct {
+	widget.BaseWidget
+
+	// OnOpened is called with the selection when a file is double-clicked or
+	// Enter is pressed. Opening a single folder lists it in the browser instead.
+	OnOpened func(uris []fyne.URI)
+	// OnSelectionChanged is called with the selection, in the order Selected
+	// returns it, whenever it changes.
+	OnSelectionChanged func(uris []fyne.URI)
+	// OnLocationChanged is called when the browser lists a different folder.
+	OnLocationChanged func(dir fyne.ListableURI)
+
+	picker  *fileDialog
+	content fyne.CanvasObject
+}
+
+// NewFileBrowser creates a file browser listing opts.Location, or the home
+// folder. parent is the window the browser is shown in, used for its menus.
+// Title, ConfirmLabel, DismissLabel and FileName are not used.
+func NewFileBrowser(parent fyne.Window, opts Options) *FileBrowser {
+	b := &FileBrowser{}
+	f := newDialogWithOptions(parent, openDialogModeFileOrFolder, opts)
+	f.embedded = true
+	f.uriCallback = func(uris []fyne.URI, _ error) {
+		if b.OnOpened != nil {
+			b.OnOpened(uris)
+		}
+	}
+	// The fields are called after any hooks given in opts.
+	onSelection, onLocation := f.hooks.onSelectionChanged, f.hooks.onLocationChanged
+	f.hooks.onSelectionChanged = func(uris []fyne.URI) {
+		if onSelection != nil {
+			onSelection(uris)
+		}
+		if b.OnSelectionChanged != nil {
+			b.OnSelectionChanged(uris)
+		}
+	}
+	f.hooks.onLocationChanged = func(dir fyne.ListableURI) {
+		if onLocation != nil {
+			onLocation(dir)
+		}
+		if b.OnLocationChanged != nil {
+			b.OnLocationChanged(dir)
+		}
+	}
+	f.browser = b
+	b.picker = f
+
+	b.content = f.newResizeRoot(f.makeBrowser(nil))
+	f.updatePreviewVisibility()
+	f.refreshDir(f.dir)
+	f.applyPreselection()
+	b.ExtendBaseWidget(b)
+	return b
+}
+
+// CreateRenderer is a private method to Fyne which links this widget to its renderer.
+func (b *FileBrowser) CreateRenderer() fyne.WidgetRenderer {
+	b.picker.attachKeys()
+	return &fileBrowserRenderer{WidgetRenderer: widget.NewSimpleRenderer(b.content), picker: b.picker}
+}
+
+// fileBrowserRenderer gives the parent window's keys back when the browser
+// is removed from it.
+type fileBrowserRenderer struct {
+	fyne.WidgetRenderer
+	picker *fileDialog
+}
+
+func (r *fileBrowserRenderer) Destroy() {
+	r.picker.detachKeys()
+	r.WidgetRenderer.Destroy()
+}
+
+// Picker returns the browser's FilePicker, for driving it from code.
+func (b *FileBrowser) Picker() FilePicker {
+	return b.picker
+}
+
+// Location returns the folder being listed.
+func (b *FileBrowser) Location() fyne.ListableURI {
+	return b.picker.dir
+}
+
+// SetLocation lists dir.
+func (b *FileBrowser) SetLocation(dir fyne.ListableURI) {
+	b.picker.SetLocation(dir)
+}
+
+// Selected returns the selected items in the order they would be returned by
+// a dialog.
+func (b *FileBrowser) Selected() []fyne.URI {
+	return b.picker.selectedURIs()
+}
+
+// SetSelectedURIs selects uris, moving to the folder holding the first one.
+func (b *FileBrowser) SetSelectedURIs(uris []fyne.URI) {
+	b.picker.SetSelectedURIs(uris)
+}
+
+// SetFilter limits the files listed; folders are always shown.
+func (b *FileBrowser) SetFilter(filter storage.FileFilter) {
+	b.picker.SetFilter(filter)
+}
+
+// SetView switches between the list and grid layouts.
+func (b *FileBrowser) SetView(view ViewLayout) {
+	b.picker.SetView(view)
+}
+
+// Reload lists the current folder again, picking up changes on disk.
+func (b *FileBrowser) Reload() {
+	b.picker.refreshDir(b.picker.dir)
+}
+
+// attachKeys routes the parent window's keys through a browser's key hooks.
+func (f *fileDialog) attachKeys() {
+	if f.parent == nil || f.keysAttached {
+		return
+	}
+	c := f.parent.Canvas()
+	f.originalOnTypedRune = c.OnTypedRune()
+	c.SetOnTypedRune(f.typedRuneHook)
+	f.originalOnTypedKey = c.OnTypedKey()
+	c.SetOnTypedKey(f.typedKeyHook)
+	f.keysAttached = true
+}
+
+// detachKeys restores the key handlers the parent window had before
+// attachKeys.
+func (f *fileDialog) detachKeys() {
+	if f.parent == nil || !f.keysAttached {
+		return
+	}
+	c := f.parent.Canvas()
+	c.SetOnTypedRune(f.originalOnTypedRune)
+	c.SetOnTypedKey(f.originalOnTypedKey)
+	f.originalOnTypedRune, f.originalOnTypedKey = nil, nil
+	f.keysAttached = false
+}
+
+// handlesKeys reports whether the key hooks should act: while a dialog is
+// shown, or while a browser is visible in its window and no popup, such as a
+// dialog opened over it, is on top.
+func (f *fileDialog) handlesKeys() bool {
+	if f.browser != nil {
+		c := fyne.CurrentApp().Driver().CanvasForObject(f.browser)
+		return f.browser.Visible() && c != nil && c.Overlays().Top() == nil
+	}
+	return f.isShown()
+}
+
+// openEmbeddedSelection handles Enter in a browser: a single selected folder
+// is listed, anything else is reported through OnOpened.
+func (f *fileDialog) openEmbeddedSelection() {
+	uris := f.selectedURIs()
+	if len(uris) == 1 {
+		if l, err := storage.ListerForURI(uris[0]); err == nil {
+			f.SetLocation(l)
+			return
+		}
+	}
+	f.OpenSelection()
+}
@@ -0,0 +1,149 @@
+package dialog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+)
+
+func TestFileBrowser_Embedded(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "sub"), 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	for _, name := range []string{"a.txt", "b.png"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	dir, _ := storage.ListerForURI(storage.NewFileURI(root))
+
+	var selected []fyne.URI
+	w := a.NewWindow("Assets")
+	b := NewFileBrowser(w, Options{
+		Location:           dir,
+		AllowMultiple:      true,
+		OnSelectionChanged: func(uris []fyne.URI) { selected = uris },
+	})
+	var opened, fieldSelected []fyne.URI
+	var moved fyne.ListableURI
+	b.OnOpened = func(uris []fyne.URI) { opened = uris }
+	b.OnSelectionChanged = func(uris []fyne.URI) { fieldSelected = uris }
+	b.OnLocationChanged = func(dir fyne.ListableURI) { moved = dir }
+	w.SetContent(container.NewBorder(widget.NewLabel("Library"), nil, nil, nil, b))
+	w.Resize(fyne.NewSize(1000, 700))
+
+	picker := b.Picker()
+	if len(b.picker.fileList.filtered) != 3 || b.Location().String() != dir.String() {
+		t.Fatalf("listing = %v in %s", b.picker.fileList.filtered, b.Location())
+	}
+
+	// Folders and files can be selected together.
+	picker.Select(0)
+	picker.ToggleSelection(1)
+	if len(b.Selected()) != 2 || len(selected) != 2 || len(fieldSelected) != 2 {
+		t.Fatalf("selected %v, hooks saw %v and %v", b.Selected(), selected, fieldSelected)
+	}
+
+	picker.Select(1)
+	picker.OpenSelection()
+	if len(opened) != 1 || opened[0].Name() != "a.txt" {
+		t.Errorf("opened %v, want a.txt", opened)
+	}
+
+	// The window's keyboard drives the browser.
+	c := w.Canvas()
+	opened = nil
+	c.OnTypedKey()(&fyne.KeyEvent{Name: fyne.KeyReturn})
+	if len(opened) != 1 || opened[0].Name() != "a.txt" {
+		t.Errorf("Enter opened %v, want a.txt", opened)
+	}
+	c.OnTypedRune()(' ')
+	if !b.picker.quickLook.visible() {
+		t.Error("expected Space to open Quick Look")
+	}
+	c.OnTypedKey()(&fyne.KeyEvent{Name: fyne.KeyEscape})
+	if b.picker.quickLook.visible() {
+		t.Fatal("expected Escape to close Quick Look")
+	}
+	c.OnTypedRune()('b')
+	if b.picker.searchEntry.Text != "b" {
+		t.Errorf("expected typing to search, got %q", b.picker.searchEntry.Text)
+	}
+	b.picker.searchEntry.SetText("")
+	c.Unfocus()
+
+	// Enter on a single folder lists it.
+	picker.Select(0)
+	c.OnTypedKey()(&fyne.KeyEvent{Name: fyne.KeyReturn})
+	if moved == nil || moved.Name() != "sub" {
+		t.Fatalf("expected Enter to open sub, location hook saw %v", moved)
+	}
+	b.SetLocation(dir)
+
+	b.SetFilter(storage.NewExtensionFileFilter([]string{".png"}))
+	if len(b.picker.fileList.filtered) != 2 {
+		t.Errorf("expected the filter to apply straight away, got %v", b.picker.fileList.filtered)
+	}
+}
+
+func TestFileBrowser_KeysWithDialogOnTop(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	root := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	dir, _ := storage.ListerForURI(storage.NewFileURI(root))
+
+	w := a.NewWindow("Assets")
+	var before []rune
+	w.Canvas().SetOnTypedRune(func(r rune) { before = append(before, r) })
+	b := NewFileBrowser(w, Options{Location: dir})
+	w.SetContent(b)
+	w.Resize(fyne.NewSize(1000, 700))
+	b.Picker().Select(0)
+
+	d := NewFileOpenURIsWithOptions(func([]fyne.URI, error) {}, w, Options{Location: dir}).(*fileDialog)
+	d.Show()
+	d.Select(1)
+	c := w.Canvas()
+	c.Unfocus()
+	c.OnTypedRune()(' ')
+	if b.picker.quickLook.visible() || !d.quickLook.visible() {
+		t.Fatal("expected Space to open the dialog's Quick Look only")
+	}
+	c.OnTypedRune()(' ')
+	c.OnTypedRune()('x')
+	if b.picker.searchEntry.Text != "" || d.searchEntry.Text != "x" {
+		t.Errorf("typing reached the browser %q, dialog %q", b.picker.searchEntry.Text, d.searchEntry.Text)
+	}
+	d.Hide()
+
+	// With the dialog gone the browser takes the keys again.
+	c.Unfocus()
+	c.OnTypedRune()('y')
+	if b.picker.searchEntry.Text != "y" {
+		t.Errorf("expected typing to search the browser, got %q", b.picker.searchEntry.Text)
+	}
+
+	// Removing the browser gives the window its own handlers back.
+	test.TempWidgetRenderer(t, b).Destroy()
+	before = nil
+	c.OnTypedRune()('z')
+	if len(before) != 1 || b.picker.searchEntry.Text != "y" {
+		t.Errorf("expected the original handler alone after removal, got %q and %q", before, b.picker.searchEntry.Text)
+	}
+}
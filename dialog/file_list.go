@@ -1,6 +1,8 @@
 package dialog
 
 import (
+	"context"
+	"image"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -19,6 +21,8 @@ import (
 
 type fileList struct {
 	picker FilePicker
+	// thumbs generates thumbnails for the items; nil uses the shared manager.
+	thumbs *ThumbnailManager
 
 	content *container.Scroll
 	view    ViewLayout
@@ -27,6 +31,8 @@ type fileList struct {
 	files        []fyne.URI
 	filtered     []fyne.URI
 	activeFilter string
+	// cancelFilter stops the background metadata search of the previous query.
+	cancelFilter context.CancelFunc
 
 	// Sorting
 	sortOrder FileSortOrder
@@ -81,7 +87,7 @@ func newFileList(p FilePicker) *fileList {
 
 	f.grid = widget.NewGridWrap(
 		func() int { return len(f.filtered) },
-		func() fyne.CanvasObject { return newFileItem(f.picker, f.getZoom, itemSize) },
+		func() fyne.CanvasObject { return f.newItem(itemSize) },
 		func(id widget.GridWrapItemID, o fyne.CanvasObject) {
 			item := o.(*fileItem)
 			item.id = int(id)
@@ -95,7 +101,7 @@ func newFileList(p FilePicker) *fileList {
 
 	f.list = widget.NewList(
 		func() int { return len(f.filtered) },
-		func() fyne.CanvasObject { return newFileItem(f.picker, f.getZoom, itemSize) },
+		func() fyne.CanvasObject { return f.newItem(itemSize) },
 		func(id widget.ListItemID, o fyne.CanvasObject) {
 			item := o.(*fileItem)
 			item.id = id
@@ -110,6 +116,20 @@ func newFileList(p FilePicker) *fileList {
 	return f
 }
 
+func (f *fileList) newItem(itemSize func(view ViewLayout, zoom float32) fyne.Size) *fileItem {
+	item := newFileItem(f.picker, f.getZoom, itemSize)
+	item.thumbs = f.thumbs
+	return item
+}
+
+// thumbnails returns the manager used for this list's thumbnails.
+func (f *fileList) thumbnails() *ThumbnailManager {
+	if f.thumbs != nil {
+		return f.thumbs
+	}
+	return GetThumbnailManager()
+}
+
 func (f *fileList) onResize() {
 	if f == nil || f.view != GridView || f.grid == nil {
 		return
@@ -185,17 +205,18 @@ func (f *fileList) setView(view ViewLayout) {
 	f.refresh()
 
 	if f.view == GridView {
-		GetThumbnailManager().PrewarmDirectory(f.files)
+		f.thumbnails().PrewarmDirectory(f.files)
 	}
 }
 
 func (f *fileList) setFiles(files []fyne.URI) {
+	f.stopMetadataSearch()
 	f.files = files
 	f.filterAndSort()
 	f.refresh()
 
 	if f.view == GridView {
-		GetThumbnailManager().PrewarmDirectory(f.files)
+		f.thumbnails().PrewarmDirectory(f.files)
 	}
 }
 
@@ -205,23 +226,72 @@ func (f *fileList) filterAndSort() {
 	f.sort()
 }
 
+// setFilter shows the files whose name contains the filter text. Metadata
+// terms such as "camera:X100V" or "taken:2025-06" narrow the results further;
+// metadata that has not been read yet is matched in the background.
 func (f *fileList) setFilter(filter string) {
-	f.activeFilter = strings.ToLower(filter)
+	f.stopMetadataSearch()
+	text, metaFilters := parseSearchQuery(filter)
+	f.activeFilter = strings.ToLower(text)
 	if filter == "" {
 		f.filtered = make([]fyne.URI, len(f.files))
 		copy(f.filtered, f.files)
 	} else {
+		var named []fyne.URI
 		f.filtered = nil
 		for _, file := range f.files {
-			if strings.Contains(strings.ToLower(file.Name()), f.activeFilter) {
+			if !strings.Contains(strings.ToLower(file.Name()), f.activeFilter) {
+				continue
+			}
+			named = append(named, file)
+			if f.thumbnails().matchesCachedMetadata(file, metaFilters) {
 				f.filtered = append(f.filtered, file)
 			}
 		}
+		if len(metaFilters) > 0 {
+			f.searchMetadata(named, metaFilters)
+		}
 	}
 	f.sort()
 	f.refresh()
 }
 
+// searchMetadata matches files against metadata filters in the background,
+// as reading EXIF or probing videos is slow, and shows the results when done.
+// The cached matches are listed in the meantime.
+func (f *fileList) searchMetadata(files []fyne.URI, filters []metadataFilter) {
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancelFilter = cancel
+	thumbs := f.thumbnails()
+	go func() {
+		var matched []fyne.URI
+		for _, file := range files {
+			if ctx.Err() != nil {
+				return
+			}
+			if thumbs.matchesMetadata(file, filters) {
+				matched = append(matched, file)
+			}
+		}
+		fyne.Do(func() {
+			if ctx.Err() != nil {
+				return
+			}
+			f.cancelFilter = nil
+			f.filtered = matched
+			f.sort()
+			f.refresh()
+		})
+	}()
+}
+
+func (f *fileList) stopMetadataSearch() {
+	if f.cancelFilter != nil {
+		f.cancelFilter()
+		f.cancelFilter = nil
+	}
+}
+
 func (f *fileList) setSortOrder(order FileSortOrder) {
 	f.sortOrder = order
 	f.sort()
@@ -311,6 +381,7 @@ func isPadded(o fyne.CanvasObject, inner fyne.CanvasObject) bool {
 type fileItem struct {
 	widget.BaseWidget
 	picker FilePicker
+	thumbs *ThumbnailManager
 	zoom   func() float32
 	itemSz func(view ViewLayout, zoom float32) fyne.Size
 	id     int
@@ -320,8 +391,12 @@ type fileItem struct {
 	customIcon *widget.Icon
 	thumbnail  *canvas.Image
 	label      *widget.Label
+	detail     *widget.Label
 	bg         *canvas.Rectangle
 
+	formatBadge *mediaBadge
+	infoBadge   *mediaBadge
+
 	rawName         string
 	gridTruncWidth  float32
 	gridTextSize    float32
@@ -331,8 +406,23 @@ type fileItem struct {
 	currentView  ViewLayout
 	currentZoom  float32
 	currentIsDir bool
+	fileType     fileType
 	lastClick    time.Time
 	loadTimer    *time.Timer
+
+	// Hover scrubbing for videos in grid view
+	thumbImage image.Image
+	storyboard *storyboard
+	hovering   bool
+	hoverX     float32
+}
+
+// thumbnails returns the manager used for this item's thumbnails.
+func (i *fileItem) thumbnails() *ThumbnailManager {
+	if i.thumbs != nil {
+		return i.thumbs
+	}
+	return GetThumbnailManager()
 }
 
 func newFileItem(p FilePicker, zoom func() float32, itemSize func(view ViewLayout, zoom float32) fyne.Size) *fileItem {
@@ -344,13 +434,20 @@ func newFileItem(p FilePicker, zoom func() float32, itemSize func(view ViewLayou
 		customIcon: widget.NewIcon(nil),
 		thumbnail:  canvas.NewImageFromImage(nil),
 		label:      widget.NewLabel(""),
+		detail:     widget.NewLabelWithStyle("", fyne.TextAlignTrailing, fyne.TextStyle{}),
 		bg:         canvas.NewRectangle(theme.Color(theme.ColorNameSelection)),
+
+		formatBadge: newMediaBadge(),
+		infoBadge:   newMediaBadge(),
 	}
 	item.thumbnail.FillMode = canvas.ImageFillContain
 	item.thumbnail.Hide()
 	item.customIcon.Hide()
 	item.bg.Hide()
 	item.label.Truncation = fyne.TextTruncateEllipsis
+	item.detail.Importance = widget.LowImportance
+	item.detail.Truncation = fyne.TextTruncateEllipsis
+	item.detail.Hide()
 	item.ExtendBaseWidget(item)
 	return item
 
@@ -398,6 +495,21 @@ func (i *fileItem) setURI(u fyne.URI, view ViewLayout) {
 	i.currentView = view
 	i.currentZoom = zoom
 	i.currentIsDir = isDir
+	i.fileType = fileType{}
+	if !isDir {
+		i.fileType = i.thumbnails().itemFileType(u)
+		// Until a local file is sniffed its extension is used; bind it again
+		// if the content turns out to be something else.
+		i.thumbnails().detectFileTypeLater(u, func(ft fileType) {
+			if i.currentPath != path || i.currentView != view || ft == i.fileType {
+				return
+			}
+			i.currentPath = ""
+			i.setURI(u, view)
+		})
+	}
+	i.thumbImage = nil
+	i.storyboard = nil
 
 	if view == GridView {
 		i.label.Alignment = fyne.TextAlignCenter
@@ -425,6 +537,19 @@ func (i *fileItem) setURI(u fyne.URI, view ViewLayout) {
 	i.thumbnail.File = ""
 	i.thumbnail.Resource = nil
 	i.thumbnail.FillMode = canvas.ImageFillContain
+	i.formatBadge.Hide()
+	i.infoBadge.Hide()
+	i.detail.Hide()
+	i.detail.SetText("")
+
+	// The file icon goes by extension; prefer the sniffed type when they disagree.
+	if i.fileType.sniffed && i.fileType.mime != extensionFileType(path) {
+		if res := i.fileType.icon(); res != nil {
+			i.customIcon.SetResource(res)
+			i.icon.Hide()
+			i.customIcon.Show()
+		}
+	}
 
 	// Check for fancy folder details
 	if isDir {
@@ -453,19 +578,22 @@ func (i *fileItem) setURI(u fyne.URI, view ViewLayout) {
 		}
 
 		// Try instant memory hit
-		if img := GetThumbnailManager().LoadMemoryOnly(u.Path()); img != nil {
+		if img := i.thumbnails().LoadMemoryOnly(thumbnailID(u)); img != nil {
 			i.thumbnail.File = ""
 			i.thumbnail.Resource = nil
 			i.thumbnail.FillMode = canvas.ImageFillContain
 			i.thumbnail.Image = img.Image
+			i.thumbImage = img.Image
 			i.thumbnail.Refresh()
 			i.icon.Hide()
+			i.customIcon.Hide()
 			i.thumbnail.Show()
+			i.updateBadges()
 			return
 		}
 
 		i.loadTimer = time.AfterFunc(200*time.Millisecond, func() {
-			GetThumbnailManager().Load(u, func(img *canvas.Image) {
+			i.thumbnails().Load(u, func(img *canvas.Image) {
 				// Ensure thread safety for UI updates using fyne.Do (available since v2.6.0)
 				fyne.Do(func() {
 					if i.currentPath != u.Path() {
@@ -476,16 +604,62 @@ func (i *fileItem) setURI(u fyne.URI, view ViewLayout) {
 						i.thumbnail.Resource = nil
 						i.thumbnail.FillMode = canvas.ImageFillContain
 						i.thumbnail.Image = img.Image
+						i.thumbImage = img.Image
 						i.thumbnail.Refresh()
 						i.icon.Hide()
+						i.customIcon.Hide()
 						i.thumbnail.Show()
+						i.updateBadges()
 					}
 				})
 			})
 		})
+	} else if u.Scheme() == "file" && (i.fileType.isImage() || i.fileType.isVideo()) {
+		// The list view shows capture details or video properties in a column.
+		i.thumbnails().LoadMetadata(u, func(info mediaInfo) {
+			fyne.Do(func() {
+				if i.currentPath != u.Path() || i.currentView == GridView {
+					return
+				}
+				i.detail.SetText(info.summary())
+				i.detail.Show()
+				i.Refresh()
+			})
+		})
 	}
 }
 
+// updateBadges overlays the metadata the ThumbnailManager recorded for this item.
+// Badges are hidden at the smallest zoom level, where they would cover the preview.
+func (i *fileItem) updateBadges() {
+	var info mediaInfo
+	show := i.uri != nil && i.currentView == GridView && i.thumbnail.Visible() && i.zoomScale() > zoomLevels[0]
+	if show {
+		info, show = i.thumbnails().loadMediaInfo(thumbnailID(i.uri))
+	}
+	if !show {
+		i.formatBadge.Hide()
+		i.infoBadge.Hide()
+		return
+	}
+
+	// Videos show their duration, images their resolution.
+	detail := info.resolution()
+	if info.Duration > 0 {
+		detail = formatMediaDuration(info.Duration)
+	}
+
+	i.formatBadge.setText(info.Format)
+	i.infoBadge.setText(detail)
+	if info.Format != "" {
+		i.formatBadge.Show()
+	}
+	if detail != "" {
+		i.infoBadge.Show()
+	}
+	i.Refresh()
+}
+
 func (i *fileItem) setSelected(selected bool) {
 	if selected {
 		i.bg.Show()
@@ -514,6 +688,8 @@ func (i *fileItem) Tapped(e *fyne.PointEvent) {
 		// Follow symlinks: try to see if it's listable (folder or symlink to folder)
 		if l, err := storage.ListerForURI(i.uri); err == nil {
 			i.picker.SetLocation(l)
+		} else if fd, ok := i.picker.(*fileDialog); ok && isLocalArchive(i.uri) {
+			fd.openArchive(i.uri, i.id)
 		} else {
 			i.picker.Select(i.id)
 			i.picker.OpenSelection()
@@ -556,6 +732,74 @@ func (i *fileItem) MouseUp(e *desktop.MouseEvent) {
 	}
 }
 
+var _ desktop.Hoverable = (*fileItem)(nil)
+
+// MouseIn starts scrubbing through the storyboard of a video in grid view.
+// The storyboard is generated on first hover and cached alongside the thumbnail.
+func (i *fileItem) MouseIn(e *desktop.MouseEvent) {
+	i.hovering = true
+	i.hoverX = e.Position.X
+	if !i.canScrub() {
+		return
+	}
+	if i.storyboard != nil {
+		i.showStoryboardFrame()
+		return
+	}
+
+	u := i.uri
+	i.thumbnails().loadStoryboard(u, func(sb *storyboard) {
+		if sb == nil {
+			return
+		}
+		fyne.Do(func() {
+			if i.uri == nil || i.uri.String() != u.String() {
+				return
+			}
+			i.storyboard = sb
+			if i.hovering {
+				i.showStoryboardFrame()
+			}
+		})
+	})
+}
+
+func (i *fileItem) MouseMoved(e *desktop.MouseEvent) {
+	i.hoverX = e.Position.X
+	if i.hovering && i.storyboard != nil && i.canScrub() {
+		i.showStoryboardFrame()
+	}
+}
+
+func (i *fileItem) MouseOut() {
+	i.hovering = false
+	if i.thumbImage != nil && i.thumbnail.Image != i.thumbImage {
+		i.thumbnail.Image = i.thumbImage
+		i.thumbnail.Refresh()
+	}
+}
+
+func (i *fileItem) canScrub() bool {
+	if i.uri == nil || i.currentView != GridView || i.currentIsDir || i.thumbImage == nil {
+		return false
+	}
+	return i.fileType.isVideo()
+}
+
+// showStoryboardFrame maps the horizontal hover position to a storyboard frame.
+func (i *fileItem) showStoryboardFrame() {
+	width := i.Size().Width
+	if width <= 0 {
+		return
+	}
+	frame := i.storyboard.frameAt(i.hoverX / width)
+	if frame == nil || frame == i.thumbnail.Image {
+		return
+	}
+	i.thumbnail.Image = frame
+	i.thumbnail.Refresh()
+}
+
 func (i *fileItem) showContextMenu(pos fyne.Position) {
 	label := lang.L("Select")
 	if i.picker.IsSelected(i.uri) {
@@ -572,7 +816,12 @@ func (i *fileItem) showContextMenu(pos fyne.Position) {
 		i.picker.DismissMenu()
 	})
 
-	menu := fyne.NewMenu("", toggleItem, copyPathItem)
+	items := []*fyne.MenuItem{toggleItem, copyPathItem}
+	if p, ok := i.picker.(selectionHelpers); ok && p.IsMultiSelect() {
+		items = append(items, fyne.NewMenuItemSeparator())
+		items = append(items, selectionMenuItems(p, i.uri)...)
+	}
+	menu := fyne.NewMenu("", items...)
 	i.picker.ShowMenu(menu, pos, i)
 }
 
@@ -865,6 +1114,16 @@ func (r *fileItemRenderer) Layout(size fyne.Size) {
 			r.item.thumbnail.Move(fyne.NewPos((size.Width-iconSize.Width)/2, theme.Padding()))
 		}
 
+		// Badges sit in the top-left and bottom-right corners of the thumbnail.
+		inset := theme.Padding() / 2
+		thumbPos := fyne.NewPos((size.Width-iconSize.Width)/2, theme.Padding())
+		formatSize := r.item.formatBadge.MinSize()
+		r.item.formatBadge.Resize(formatSize)
+		r.item.formatBadge.Move(thumbPos.AddXY(inset, inset))
+		infoSize := r.item.infoBadge.MinSize()
+		r.item.infoBadge.Resize(infoSize)
+		r.item.infoBadge.Move(thumbPos.AddXY(iconSize.Width-infoSize.Width-inset, iconSize.Height-infoSize.Height-inset))
+
 		// Size the label using the available height so the last line (extension)
 		// never gets clipped due to rounding/padding differences.
 		labelY := iconSize.Height + theme.Padding()*2
@@ -889,6 +1148,14 @@ func (r *fileItemRenderer) Layout(size fyne.Size) {
 		r.item.customIcon.Move(fyne.NewPos(theme.Padding(), (size.Height-iconSize.Height)/2))
 
 		labelSize := fyne.NewSize(size.Width-iconSize.Width-theme.Padding()*3, size.Height)
+		if r.item.detail.Visible() {
+			// The detail column takes what it needs, up to half of the row.
+			textWidth := fyne.MeasureText(r.item.detail.Text, theme.TextSize(), r.item.detail.TextStyle).Width
+			detailWidth := fyne.Min(textWidth+theme.InnerPadding()*2, labelSize.Width/2)
+			r.item.detail.Resize(fyne.NewSize(detailWidth, size.Height))
+			r.item.detail.Move(fyne.NewPos(size.Width-detailWidth-theme.Padding(), 0))
+			labelSize.Width -= detailWidth
+		}
 		r.item.label.Resize(labelSize)
 		r.item.label.Move(fyne.NewPos(iconSize.Width+theme.Padding()*2, 0))
 
@@ -974,11 +1241,14 @@ func (r *fileItemRenderer) Refresh() {
 	r.item.bg.Refresh()
 	r.item.icon.Refresh()
 	r.item.customIcon.Refresh()
+	r.item.formatBadge.Refresh()
+	r.item.infoBadge.Refresh()
 	r.item.label.Refresh()
+	r.item.detail.Refresh()
 }
 
 func (r *fileItemRenderer) Objects() []fyne.CanvasObject {
-	return []fyne.CanvasObject{r.item.bg, r.item.icon, r.item.customIcon, r.item.thumbnail, r.item.label}
+	return []fyne.CanvasObject{r.item.bg, r.item.icon, r.item.customIcon, r.item.thumbnail, r.item.formatBadge, r.item.infoBadge, r.item.label, r.item.detail}
 }
 
 func (r *fileItemRenderer) Destroy() {
@@ -1113,6 +1383,15 @@ func (f *fileList) centerAnchorID(view ViewLayout, zoom float32) int {
 	}
 }
 
+// scrollTo brings the item with id into view.
+func (f *fileList) scrollTo(id int) {
+	if f.view == GridView {
+		f.grid.ScrollTo(widget.GridWrapItemID(id))
+	} else {
+		f.list.ScrollTo(widget.ListItemID(id))
+	}
+}
+
 func (f *fileList) scrollCenterOnID(view ViewLayout, id int, zoom float32) {
 	if len(f.filtered) == 0 {
 		return
@@ -1,14 +1,19 @@
 package dialog
 
 import (
+	"bytes"
 	"fmt"
+	"image"
+	imagepng "image/png"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 	"unicode/utf8"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/storage/repository"
 	"fyne.io/fyne/v2/test"
 	"fyne.io/fyne/v2/theme"
 )
@@ -154,12 +159,32 @@ func (c *contextMenuPicker) ShowMenu(menu *fyne.Menu, pos fyne.Position, obj fyn
 }
 func (c *contextMenuPicker) DismissMenu() { c.dismissCalls++ }
 
+// bulkMenuPicker also offers the bulk selection actions, as fileDialog does.
+type bulkMenuPicker struct {
+	contextMenuPicker
+}
+
+func (b *bulkMenuPicker) InvertSelection()            {}
+func (b *bulkMenuPicker) SelectMatching()             {}
+func (b *bulkMenuPicker) SelectSameType(uri fyne.URI) {}
+
 func TestFileItem_ContextMenu_CopyPath(t *testing.T) {
 	test.NewApp()
 
-	picker := &contextMenuPicker{}
-	item := newFileItem(picker, func() float32 { return 1.0 }, calculateItemSizeWithZoom)
 	uri := storage.NewFileURI("/tmp/sample-folder/sample.txt")
+
+	// Pickers without the bulk selection actions only get the basic items.
+	plain := &contextMenuPicker{}
+	item := newFileItem(plain, func() float32 { return 1.0 }, calculateItemSizeWithZoom)
+	item.setURI(uri, ListView)
+	item.showContextMenu(fyne.NewPos(10, 10))
+	if plain.menu == nil || len(plain.menu.Items) != 2 {
+		t.Fatalf("expected Select and Copy Path only, got %v", plain.menu)
+	}
+
+	bulk := &bulkMenuPicker{}
+	picker := &bulk.contextMenuPicker
+	item = newFileItem(bulk, func() float32 { return 1.0 }, calculateItemSizeWithZoom)
 	item.id = 3
 	item.setURI(uri, ListView)
 
@@ -167,8 +192,12 @@ func TestFileItem_ContextMenu_CopyPath(t *testing.T) {
 	if picker.menu == nil {
 		t.Fatal("expected context menu to be shown")
 	}
-	if len(picker.menu.Items) != 2 {
-		t.Fatalf("expected 2 context menu items, got %d", len(picker.menu.Items))
+	// Select, Copy Path, a separator and the three bulk selection actions.
+	if len(picker.menu.Items) != 6 {
+		t.Fatalf("expected 6 context menu items, got %d", len(picker.menu.Items))
+	}
+	if !picker.menu.Items[2].IsSeparator || picker.menu.Items[3].Label != "Invert Selection" {
+		t.Errorf("expected the selection actions after a separator, got %q", picker.menu.Items[3].Label)
 	}
 
 	copyPathItem := picker.menu.Items[1]
@@ -513,3 +542,62 @@ func TestStableGridLabelWidth(t *testing.T) {
 		})
 	}
 }
+
+// blockingRepository serves memRepository files once release is closed.
+type blockingRepository struct {
+	memRepository
+	release chan struct{}
+}
+
+func (b *blockingRepository) Reader(u fyne.URI) (fyne.URIReadCloser, error) {
+	<-b.release
+	return b.memRepository.Reader(u)
+}
+
+func TestFileList_MetadataFilterDoesNotBlock(t *testing.T) {
+	test.NewApp()
+	var png bytes.Buffer
+	if err := imagepng.Encode(&png, image.NewRGBA(image.Rect(0, 0, 40, 20))); err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	repo := &blockingRepository{
+		memRepository: memRepository{files: map[string][]byte{"memslow://host/a.png": png.Bytes()}},
+		release:       make(chan struct{}),
+	}
+	repository.Register("memslow", repo)
+	u, _ := storage.ParseURI("memslow://host/a.png")
+
+	fl := newFileList(&mockPicker{})
+	fl.thumbs = &ThumbnailManager{}
+	fl.setFiles([]fyne.URI{u})
+
+	filtered := make(chan int)
+	go func() {
+		fl.setFilter("res:40x20")
+		filtered <- len(fl.filtered)
+	}()
+	select {
+	case n := <-filtered:
+		if n != 0 {
+			t.Errorf("expected no cached matches yet, got %d", n)
+		}
+	case <-time.After(2 * time.Second):
+		close(repo.release)
+		t.Fatal("setFilter blocked reading uncached metadata")
+	}
+
+	// Once the metadata is read in the background, the match is listed.
+	close(repo.release)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var n int
+		fyne.DoAndWait(func() { n = len(fl.filtered) })
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the background search to list the matching file")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
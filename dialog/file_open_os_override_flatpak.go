@@ -15,6 +15,15 @@ import (
 )
 
 func fileOpenOSOverride(f *fileDialog) bool {
+	if f.isFileOrFolderMode() {
+		// The portal cannot pick files and folders together.
+		return false
+	}
+	if !f.isSaveMode() && !f.constraints.isZero() {
+		// The portal cannot enforce selection constraints.
+		return false
+	}
+
 	if f.isSaveMode() {
 		options := &filechooser.SaveFileOptions{
 			AcceptLabel: lang.L("Save"),
@@ -25,11 +34,18 @@ func fileOpenOSOverride(f *fileDialog) bool {
 		if f.defaultSaveName != "" {
 			options.CurrentName = f.defaultSaveName
 		}
+		if f.confirmLabel != "" {
+			options.AcceptLabel = f.confirmLabel
+		}
+		title := lang.L("Save File")
+		if f.title != "" {
+			title = f.title
+		}
 		options.Filters, options.CurrentFilter = convertFilterForPortal(f.extensionFilter)
 		windowHandle := windowHandleForPortal(f.parent)
 
 		go func() {
-			uris, err := filechooser.SaveFile(windowHandle, lang.L("Save File"), options)
+			uris, err := filechooser.SaveFile(windowHandle, title, options)
 			if err != nil {
 				fyne.Do(func() {
 					if f.saveCallback != nil {
@@ -75,6 +91,9 @@ func fileOpenOSOverride(f *fileDialog) bool {
 	if f.dir != nil {
 		options.CurrentFolder = f.dir.Path()
 	}
+	if f.confirmLabel != "" {
+		options.AcceptLabel = f.confirmLabel
+	}
 
 	options.Filters, options.CurrentFilter = convertFilterForPortal(f.extensionFilter)
 	windowHandle := windowHandleForPortal(f.parent)
@@ -85,13 +104,18 @@ func fileOpenOSOverride(f *fileDialog) bool {
 			titleNoun = lang.L("Folder")
 		}
 		title := lang.L("Open") + " " + titleNoun
+		if f.title != "" {
+			title = f.title
+		}
 		uris, err := filechooser.OpenFile(windowHandle, title, options)
 		if err != nil {
 			fyne.Do(func() {
 				if f.isFolderMode() {
-					if f.folderCallback != nil {
-						f.folderCallback(nil, err)
-					}
+					f.folderResult(nil, err)
+					return
+				}
+				if f.uriCallback != nil {
+					f.uriCallback(nil, err)
 					return
 				}
 				if f.callback != nil {
@@ -103,9 +127,11 @@ func fileOpenOSOverride(f *fileDialog) bool {
 		if len(uris) == 0 {
 			fyne.Do(func() {
 				if f.isFolderMode() {
-					if f.folderCallback != nil {
-						f.folderCallback(nil, nil)
-					}
+					f.folderResult(nil, nil)
+					return
+				}
+				if f.uriCallback != nil {
+					f.uriCallback(nil, nil)
 					return
 				}
 				if f.callback != nil {
@@ -116,42 +142,50 @@ func fileOpenOSOverride(f *fileDialog) bool {
 		}
 
 		if f.isFolderMode() {
-			uri, parseErr := storage.ParseURI(uris[0])
-			if parseErr != nil {
-				err = parseErr
+			dirs := make([]fyne.ListableURI, 0, len(uris))
+			for _, raw := range uris {
+				uri, parseErr := storage.ParseURI(raw)
+				if parseErr != nil {
+					err = parseErr
+					break
+				}
+				dir, listErr := storage.ListerForURI(uri)
+				if listErr != nil {
+					err = listErr
+					break
+				}
+				dirs = append(dirs, dir)
 			}
-			var dir fyne.ListableURI
-			if err == nil {
-				dir, err = storage.ListerForURI(uri)
+			if err != nil {
+				dirs = nil
 			}
 			fyne.Do(func() {
-				if f.folderCallback != nil {
-					f.folderCallback(dir, err)
-				}
+				f.folderResult(dirs, err)
 			})
 			return
 		}
 
-		readers := make([]fyne.URIReadCloser, 0, len(uris))
+		parsed := make([]fyne.URI, 0, len(uris))
 		for _, raw := range uris {
 			uri, parseErr := storage.ParseURI(raw)
 			if parseErr != nil {
 				err = parseErr
+				parsed = nil
 				break
 			}
-			r, openErr := storage.Reader(uri)
-			if openErr != nil {
-				err = openErr
-				break
-			}
-			readers = append(readers, r)
+			parsed = append(parsed, uri)
 		}
 
-		if err != nil {
-			for _, r := range readers {
-				_ = r.Close()
-			}
-			readers = nil
+		if f.uriCallback != nil {
+			fyne.Do(func() {
+				f.uriCallback(parsed, err)
+			})
+			return
+		}
+
+		var readers []fyne.URIReadCloser
+		if err == nil {
+			readers, err = openReaders(parsed)
 		}
 
 		fyne.Do(func() {
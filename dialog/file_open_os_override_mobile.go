@@ -28,12 +28,35 @@ func fileOpenOSOverride(f *fileDialog) bool {
 
 	if f.isFolderMode() {
 		d := fynedialog.NewFolderOpen(func(dir fyne.ListableURI, err error) {
+			var dirs []fyne.ListableURI
+			if dir != nil {
+				dirs = []fyne.ListableURI{dir}
+			}
+			fyne.Do(func() {
+				f.folderResult(dirs, err)
+			})
+		}, f.parent)
+		d.Show()
+		return true
+	}
+
+	if f.uriCallback != nil {
+		// Mobile pickers choose files only; the URI is returned without the reader.
+		d := fynedialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			var uris []fyne.URI
+			if reader != nil {
+				uris = []fyne.URI{reader.URI()}
+				_ = reader.Close()
+			}
 			fyne.Do(func() {
-				if f.folderCallback != nil {
-					f.folderCallback(dir, err)
+				if f.uriCallback != nil {
+					f.uriCallback(uris, err)
 				}
 			})
 		}, f.parent)
+		if f.extensionFilter != nil {
+			d.SetFilter(f.extensionFilter)
+		}
 		d.Show()
 		return true
 	}
@@ -0,0 +1,17 @@
+//go:build darwin
+
+package dialog
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+func fileCreatedTime(info os.FileInfo) (time.Time, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(stat.Birthtimespec.Unix()), true
+}
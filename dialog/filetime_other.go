@@ -0,0 +1,13 @@
+//go:build !windows && !darwin
+
+package dialog
+
+import (
+	"os"
+	"time"
+)
+
+func fileCreatedTime(info os.FileInfo) (time.Time, bool) {
+	// Creation time is not exposed through syscall.Stat_t on these platforms.
+	return time.Time{}, false
+}
@@ -0,0 +1,17 @@
+//go:build windows
+
+package dialog
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+func fileCreatedTime(info os.FileInfo) (time.Time, bool) {
+	attrs, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(0, attrs.CreationTime.Nanoseconds()), true
+}
@@ -0,0 +1,383 @@
+package dialog
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+)
+
+// sniffLen is how much of a file is read to look for a signature.
+const sniffLen = 512
+
+// fileType is the detected content type of a file.
+type fileType struct {
+	// mime is the detected MIME type, or "" if unknown.
+	mime string
+	// sniffed is true when mime was confirmed by the file's signature.
+	sniffed bool
+	// text is true when the content looks like text, whatever the extension says.
+	text bool
+}
+
+// extensionTypes covers the media extensions missing from the built-in table of package mime.
+var extensionTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+	".mp4":  "video/mp4",
+	".m4v":  "video/mp4",
+	".mkv":  "video/x-matroska",
+	".webm": "video/webm",
+	".avi":  "video/x-msvideo",
+	".mov":  "video/quicktime",
+	".mp3":  "audio/mpeg",
+	".flac": "audio/flac",
+	".m4a":  "audio/mp4",
+	".m4b":  "audio/mp4",
+	".wav":  "audio/wav",
+	".pdf":  "application/pdf",
+	".zip":  "application/zip",
+}
+
+// sniffableTypes are the types sniffFileType recognises. A file whose extension
+// claims one of them but whose content is text is not trusted.
+var sniffableTypes = map[string]bool{
+	"image/jpeg": true, "image/png": true, "image/gif": true, "image/webp": true,
+	"image/avif": true, "image/heic": true,
+	"video/mp4": true, "video/quicktime": true, "video/x-matroska": true, "video/webm": true, "video/x-msvideo": true,
+	"audio/mpeg": true, "audio/flac": true, "audio/mp4": true, "audio/wav": true,
+	"application/pdf": true, "application/zip": true,
+}
+
+// sniffFileType matches the leading bytes of a file against known signatures.
+// ext, the lower case file extension, breaks ties between formats sharing a
+// signature. Returns "" if none match.
+func sniffFileType(head []byte, ext string) string {
+	switch {
+	case bytes.HasPrefix(head, []byte{0xff, 0xd8, 0xff}):
+		return "image/jpeg"
+	case bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1a\n")):
+		return "image/png"
+	case bytes.HasPrefix(head, []byte("GIF87a")), bytes.HasPrefix(head, []byte("GIF89a")):
+		return "image/gif"
+	case len(head) >= 12 && string(head[:4]) == "RIFF":
+		switch string(head[8:12]) {
+		case "WEBP":
+			return "image/webp"
+		case "AVI ":
+			return "video/x-msvideo"
+		case "WAVE":
+			return "audio/wav"
+		}
+	case len(head) >= 12 && string(head[4:8]) == "ftyp":
+		// ISO base media file: the major brand tells the flavours apart.
+		switch string(head[8:12]) {
+		case "M4A ", "M4B ":
+			return "audio/mp4"
+		case "qt  ":
+			return "video/quicktime"
+		case "avif", "avis":
+			return "image/avif"
+		case "heic", "heix", "mif1", "msf1":
+			return "image/heic"
+		}
+		// Generic brands such as isom, mp42 and dash are used for audio too.
+		if extensionTypes[ext] == "audio/mp4" {
+			return "audio/mp4"
+		}
+		return "video/mp4"
+	case bytes.HasPrefix(head, []byte{0x1a, 0x45, 0xdf, 0xa3}):
+		// EBML header; the DocType distinguishes WebM from Matroska.
+		if bytes.Contains(head, []byte("webm")) {
+			return "video/webm"
+		}
+		return "video/x-matroska"
+	case bytes.HasPrefix(head, []byte("%PDF-")):
+		return "application/pdf"
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")), bytes.HasPrefix(head, []byte("PK\x05\x06")):
+		return "application/zip"
+	case bytes.HasPrefix(head, []byte("ID3")), bytes.HasPrefix(head, []byte("fLaC")):
+		if head[0] == 'f' {
+			return "audio/flac"
+		}
+		return "audio/mpeg"
+	case len(head) >= 2 && head[0] == 0xff && head[1]&0xe0 == 0xe0 && (head[1]>>1)&0x3 != 0:
+		// MPEG audio frame sync; ADTS AAC has a zero layer and is excluded.
+		return "audio/mpeg"
+	}
+	return ""
+}
+
+// looksLikeText reports whether head is UTF-16 with a byte order mark, or
+// UTF-8 without control characters other than common whitespace.
+func looksLikeText(head []byte) bool {
+	if len(head) == 0 {
+		return false
+	}
+	if bytes.HasPrefix(head, []byte{0xfe, 0xff}) || bytes.HasPrefix(head, []byte{0xff, 0xfe}) {
+		return true
+	}
+	if len(head) == sniffLen {
+		// Ignore a multi-byte rune cut off by the sniff buffer.
+		for i := 0; i < utf8.UTFMax-1 && !utf8.Valid(head); i++ {
+			head = head[:len(head)-1]
+		}
+	}
+	if !utf8.Valid(head) {
+		return false
+	}
+	for _, b := range head {
+		if b < 0x20 && b != '\t' && b != '\n' && b != '\r' && b != '\f' && b != 0x1b {
+			return false
+		}
+	}
+	return true
+}
+
+// extensionFileType returns the MIME type implied by the file extension, or "".
+func extensionFileType(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == "" {
+		return ""
+	}
+	if t, ok := extensionTypes[ext]; ok {
+		return t
+	}
+	t, _, _ := mime.ParseMediaType(mime.TypeByExtension(ext))
+	return t
+}
+
+// combineFileType merges the extension and signature results. A signature
+// wins; without one the extension type is kept, as many valid files, such as
+// videos starting with a wide or mdat atom, have no recognisable header.
+func combineFileType(extType, sniffed string) fileType {
+	if sniffed != "" {
+		return fileType{mime: sniffed, sniffed: true}
+	}
+	return fileType{mime: extType}
+}
+
+type fileTypeEntry struct {
+	ft      fileType
+	size    int64
+	modTime time.Time
+}
+
+const (
+	// maxFileTypes is how many detection results a ThumbnailManager keeps.
+	maxFileTypes = 10000
+	// maxConcurrentDetections is how many files are sniffed at once in the background.
+	maxConcurrentDetections = 4
+)
+
+// fileTypeCache returns the detection results by URI. Local entries are
+// revalidated against their size and modification time.
+func (m *ThumbnailManager) fileTypeCache() *lruCache[fileTypeEntry] {
+	m.fileTypesOnce.Do(func() {
+		m.fileTypes = &lruCache[fileTypeEntry]{limit: maxFileTypes}
+		m.typeSlots = make(chan struct{}, maxConcurrentDetections)
+	})
+	return m.fileTypes
+}
+
+// detectFileType identifies the content of u from its signature and extension.
+// Reading the signature may block on the underlying repository.
+func (m *ThumbnailManager) detectFileType(u fyne.URI) fileType {
+	id := u.String()
+	var stat os.FileInfo
+	if u.Scheme() == "file" {
+		stat, _ = os.Stat(u.Path())
+	}
+
+	cache := m.fileTypeCache()
+	if entry, ok := cache.get(id); ok {
+		if stat == nil || (stat.Size() == entry.size && stat.ModTime().Equal(entry.modTime)) {
+			return entry.ft
+		}
+	}
+
+	extType := extensionFileType(u.Path())
+	var ft fileType
+	if stat != nil && stat.IsDir() {
+		ft = fileType{}
+	} else {
+		head, err := readFileHead(u)
+		ft = combineFileType(extType, sniffFileType(head, strings.ToLower(filepath.Ext(u.Path()))))
+		if err == nil && !ft.sniffed && looksLikeText(head) {
+			ft.text = true
+			// Text is never a valid image, video or archive.
+			if ft.mime == "" || sniffableTypes[ft.mime] {
+				ft = fileType{mime: "text/plain", sniffed: true, text: true}
+			}
+		}
+	}
+
+	entry := fileTypeEntry{ft: ft}
+	if stat != nil {
+		entry.size, entry.modTime = stat.Size(), stat.ModTime()
+	}
+	cache.add(id, entry)
+	return ft
+}
+
+// itemFileType is detectFileType for the UI thread. Nothing is read: the last
+// detected type is returned, or the extension type for files that have not
+// been detected yet; see detectFileTypeLater.
+func (m *ThumbnailManager) itemFileType(u fyne.URI) fileType {
+	if entry, ok := m.fileTypeCache().get(u.String()); ok {
+		return entry.ft
+	}
+	return fileType{mime: extensionFileType(u.Path())}
+}
+
+// needsDetection reports whether u is a local file whose type has not been
+// detected yet. Other URIs are not read while listing.
+func (m *ThumbnailManager) needsDetection(u fyne.URI) bool {
+	if u.Scheme() != "file" {
+		return false
+	}
+	_, ok := m.fileTypeCache().get(u.String())
+	return !ok
+}
+
+// detectFileTypeLater detects the type of a local file that has not been
+// detected yet in the background, calling back on the UI thread when done.
+// Nothing happens for files detected before and for other URIs.
+func (m *ThumbnailManager) detectFileTypeLater(u fyne.URI, callback func(fileType)) {
+	if !m.needsDetection(u) {
+		return
+	}
+	id := u.String()
+	m.typeLock.Lock()
+	waiters, busy := m.typeWaiters[id]
+	if m.typeWaiters == nil {
+		m.typeWaiters = make(map[string][]func(fileType))
+	}
+	m.typeWaiters[id] = append(waiters, callback)
+	m.typeLock.Unlock()
+	if busy {
+		return
+	}
+
+	go func() {
+		m.typeSlots <- struct{}{}
+		ft := m.detectFileType(u)
+		<-m.typeSlots
+
+		m.typeLock.Lock()
+		waiters := m.typeWaiters[id]
+		delete(m.typeWaiters, id)
+		m.typeLock.Unlock()
+		fyne.Do(func() {
+			for _, waiter := range waiters {
+				waiter(ft)
+			}
+		})
+	}()
+}
+
+func readFileHead(u fyne.URI) ([]byte, error) {
+	var r io.ReadCloser
+	var err error
+	if u.Scheme() == "file" {
+		r, err = os.Open(u.Path())
+	} else {
+		r, err = storage.Reader(u)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, head)
+	if err == io.ErrUnexpectedEOF || err == io.EOF {
+		err = nil
+	}
+	return head[:n], err
+}
+
+func (t fileType) isImage() bool {
+	// Only formats with a registered decoder.
+	switch t.mime {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return true
+	}
+	return false
+}
+
+func (t fileType) isVideo() bool {
+	return strings.HasPrefix(t.mime, "video/")
+}
+
+// isAudio reports whether the type is an audio format we can read cover art from.
+func (t fileType) isAudio() bool {
+	switch t.mime {
+	case "audio/mpeg", "audio/flac", "audio/mp4":
+		return true
+	}
+	return false
+}
+
+// icon returns a generic icon for the detected type, or nil for unknown types.
+func (t fileType) icon() fyne.Resource {
+	switch {
+	case strings.HasPrefix(t.mime, "image/"):
+		return theme.FileImageIcon()
+	case strings.HasPrefix(t.mime, "video/"):
+		return theme.FileVideoIcon()
+	case strings.HasPrefix(t.mime, "audio/"):
+		return theme.FileAudioIcon()
+	case t.text || strings.HasPrefix(t.mime, "text/"):
+		return theme.FileTextIcon()
+	case t.mime != "":
+		return theme.FileApplicationIcon()
+	}
+	return nil
+}
+
+// mimeTypeMatches reports whether mimeType matches pattern, which may use
+// a "*" wildcard for either part, e.g. "image/*".
+func mimeTypeMatches(pattern, mimeType string) bool {
+	pType, pSub, ok := strings.Cut(pattern, "/")
+	if !ok {
+		return false
+	}
+	mType, mSub, ok := strings.Cut(mimeType, "/")
+	if !ok {
+		return false
+	}
+	mSub, _, _ = strings.Cut(mSub, ";")
+	return (pType == "*" || pType == mType) && (pSub == "*" || pSub == mSub)
+}
+
+// matchesFileFilter applies filter to file without reading it. MIME type
+// filters are matched against the detected content type where it is known,
+// see itemFileType, and by extension otherwise.
+func (m *ThumbnailManager) matchesFileFilter(filter storage.FileFilter, file fyne.URI) bool {
+	mimeFilter, ok := filter.(*storage.MimeTypeFileFilter)
+	if !ok {
+		return filter.Matches(file)
+	}
+	ft := m.itemFileType(file)
+	if !ft.sniffed {
+		return filter.Matches(file)
+	}
+	for _, pattern := range mimeFilter.MimeTypes {
+		if mimeTypeMatches(pattern, ft.mime) {
+			return true
+		}
+	}
+	return false
+}
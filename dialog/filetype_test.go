@@ -0,0 +1,187 @@
+package dialog
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/storage/repository"
+	"fyne.io/fyne/v2/test"
+)
+
+func TestSniffFileType(t *testing.T) {
+	cases := []struct {
+		name string
+		head []byte
+		ext  string
+		want string
+	}{
+		{"jpeg", []byte{0xff, 0xd8, 0xff, 0xe0}, "", "image/jpeg"},
+		{"png", []byte("\x89PNG\r\n\x1a\n...."), "", "image/png"},
+		{"gif", []byte("GIF89a...."), "", "image/gif"},
+		{"webp", []byte("RIFF\x00\x00\x00\x00WEBPVP8 "), "", "image/webp"},
+		{"avi", []byte("RIFF\x00\x00\x00\x00AVI LIST"), "", "video/x-msvideo"},
+		{"mp4", []byte("\x00\x00\x00\x18ftypisom"), ".mp4", "video/mp4"},
+		{"mov", []byte("\x00\x00\x00\x14ftypqt  "), ".mov", "video/quicktime"},
+		{"m4a", []byte("\x00\x00\x00\x1cftypM4A "), "", "audio/mp4"},
+		{"m4a generic brand", []byte("\x00\x00\x00\x1cftypmp42"), ".m4a", "audio/mp4"},
+		{"m4b dash brand", []byte("\x00\x00\x00\x1cftypdash"), ".m4b", "audio/mp4"},
+		{"mkv", []byte("\x1a\x45\xdf\xa3\x9f\x42\x82\x88matroska"), "", "video/x-matroska"},
+		{"webm", []byte("\x1a\x45\xdf\xa3\x9f\x42\x82\x84webm"), "", "video/webm"},
+		{"pdf", []byte("%PDF-1.7"), "", "application/pdf"},
+		{"zip", []byte("PK\x03\x04"), "", "application/zip"},
+		{"id3", []byte("ID3\x03\x00"), "", "audio/mpeg"},
+		{"mp3 frame", []byte{0xff, 0xfb, 0x90, 0x00}, "", "audio/mpeg"},
+		{"flac", []byte("fLaC\x00"), "", "audio/flac"},
+		{"aac adts", []byte{0xff, 0xf1, 0x50, 0x80}, "", ""},
+		{"text", []byte("hello"), "", ""},
+		{"empty", nil, "", ""},
+	}
+	for _, tc := range cases {
+		if got := sniffFileType(tc.head, tc.ext); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestDetectFileType_PrefersSignature(t *testing.T) {
+	m := &ThumbnailManager{}
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, data, 0o644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+		return p
+	}
+	png := testPNG(t, color.White)
+
+	// A renamed or extensionless image is still an image.
+	for _, name := range []string{"photo.dat", "DCIM0001"} {
+		ft := m.detectFileType(storage.NewFileURI(write(name, png)))
+		if !ft.isImage() || !ft.sniffed {
+			t.Errorf("%s: expected sniffed image, got %+v", name, ft)
+		}
+	}
+
+	// A broken file with an image extension is not treated as an image.
+	if ft := m.detectFileType(storage.NewFileURI(write("broken.png", []byte("not a png")))); ft.isImage() {
+		t.Errorf("expected broken.png not to be an image, got %+v", ft)
+	}
+
+	// Valid media without a recognisable header keeps its extension type.
+	for name, want := range map[string]string{"wide.mov": "video/quicktime", "mdat.mp4": "video/mp4"} {
+		ft := m.detectFileType(storage.NewFileURI(write(name, []byte("\x00\x00\x00\x08wide\x00\x10\x00\x00mdat\x00\x81"))))
+		if ft.mime != want || !ft.isVideo() {
+			t.Errorf("%s: expected %s from extension, got %+v", name, want, ft)
+		}
+	}
+	if ft := m.detectFileType(storage.NewFileURI(write("song.m4a", []byte("\x00\x00\x00\x1cftypmp42\x00\x00\x00\x00")))); ft.mime != "audio/mp4" {
+		t.Errorf("expected generic brand .m4a to be audio, got %+v", ft)
+	}
+
+	// Unsniffable formats keep their extension type.
+	if ft := m.detectFileType(storage.NewFileURI(write("notes.txt", []byte("hello")))); ft.mime != "text/plain" {
+		t.Errorf("expected text/plain from extension, got %+v", ft)
+	}
+
+	// Cached results are revalidated when the file changes.
+	p := write("changes.bin", []byte("plain"))
+	if ft := m.detectFileType(storage.NewFileURI(p)); ft.isImage() {
+		t.Fatalf("unexpected image type %+v", ft)
+	}
+	write("changes.bin", png)
+	if ft := m.detectFileType(storage.NewFileURI(p)); !ft.isImage() {
+		t.Errorf("expected cache to pick up rewritten content, got %+v", ft)
+	}
+}
+
+func TestMatchesFileFilter_UsesSniffedMIME(t *testing.T) {
+	dir := t.TempDir()
+	renamed := filepath.Join(dir, "image.dat")
+	if err := os.WriteFile(renamed, testPNG(t, color.White), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	fake := filepath.Join(dir, "fake.png")
+	if err := os.WriteFile(fake, []byte("not a png"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	m := &ThumbnailManager{}
+	filter := storage.NewMimeTypeFileFilter([]string{"image/*"})
+	// Nothing is read while matching; undetected files go by extension.
+	if m.matchesFileFilter(filter, storage.NewFileURI(renamed)) || !m.needsDetection(storage.NewFileURI(renamed)) {
+		t.Error("expected the undetected image.dat to be matched by extension")
+	}
+	m.detectFileType(storage.NewFileURI(renamed))
+	if !m.matchesFileFilter(filter, storage.NewFileURI(renamed)) {
+		t.Error("expected renamed PNG to match image/* once detected")
+	}
+	if (&ThumbnailManager{}).needsDetection(storage.NewFileURI(renamed)) == false {
+		t.Error("expected detection results to be kept per manager")
+	}
+	if !mimeTypeMatches("image/png", "image/png; charset=binary") || mimeTypeMatches("video/*", "image/png") {
+		t.Error("unexpected mimeTypeMatches result")
+	}
+
+	// Other repositories are matched by extension without being read.
+	repo := &countingMemRepository{memRepository: memRepository{files: map[string][]byte{"memfilter://host/a.png": []byte("x")}}}
+	repository.Register("memfilter", repo)
+	remote, _ := storage.ParseURI("memfilter://host/a.png")
+	if !m.matchesFileFilter(filter, remote) || repo.reads != 0 {
+		t.Errorf("expected remote a.png to match image/* unread, got %d reads", repo.reads)
+	}
+
+	ext := storage.NewExtensionFileFilter([]string{".png"})
+	if !m.matchesFileFilter(ext, storage.NewFileURI(fake)) {
+		t.Error("extension filters should keep matching by name")
+	}
+}
+
+func TestFileDialog_MimeFilterDetectsInBackground(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"image.dat": testPNG(t, color.White),
+		"fake.png":  []byte("not a png"),
+		"real.png":  testPNG(t, color.Black),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	lister, _ := storage.ListerForURI(storage.NewFileURI(dir))
+
+	d := NewFileOpenURIsWithOptions(func([]fyne.URI, error) {}, a.NewWindow("Test"), Options{
+		Location:         lister,
+		Filter:           storage.NewMimeTypeFileFilter([]string{"image/*"}),
+		ThumbnailManager: NewThumbnailManager(ThumbnailOptions{}),
+	}).(*fileDialog)
+	d.Show()
+
+	// Once sniffed, the listing follows the content rather than the extension.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var names []string
+		fyne.DoAndWait(func() {
+			for _, u := range d.fileList.filtered {
+				names = append(names, u.Name())
+			}
+		})
+		if slices.Equal(names, []string{"image.dat", "real.png"}) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("listing = %v, want the detected images", names)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,257 @@
+package dialog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/storage"
+	"golang.org/x/image/draw"
+)
+
+const (
+	defaultFolderMosaicMaxEntries = 500
+	// folderMosaicMaxAttempts caps how many children we try to thumbnail, so a
+	// folder of unreadable media does not fill the queue.
+	folderMosaicMaxAttempts = 8
+)
+
+var errFolderMosaicSkipped = errors.New("folder mosaic skipped")
+
+// folderMosaicEntries lists a folder for mosaic generation, refusing folders
+// that exceed the configured entry budget. Only direct children are read.
+func (m *ThumbnailManager) folderMosaicEntries(path string) ([]os.DirEntry, error) {
+	limit := m.Options().FolderMosaicMaxEntries
+	if limit <= 0 {
+		limit = defaultFolderMosaicMaxEntries
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// Read one past the budget so huge folders are rejected without listing them fully.
+	entries, err := f.ReadDir(limit + 1)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if len(entries) > limit {
+		return nil, errFolderMosaicSkipped
+	}
+	// Unlike os.ReadDir, File.ReadDir returns entries in directory order.
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// folderCacheKey derives a cache key from the folder path and the names,
+// sizes and modification times of its children.
+func (m *ThumbnailManager) folderCacheKey(absPath string) (string, error) {
+	entries, err := m.folderMosaicEntries(absPath)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write([]byte("mosaic:" + absPath))
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		h.Write([]byte(fmt.Sprintf("\x00%s\x00%d\x00%s", e.Name(), info.Size(), info.ModTime().String())))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// folderMosaicCandidates lists the images and videos in a folder that a
+// mosaic is made from, in name order.
+func (m *ThumbnailManager) folderMosaicCandidates(path string) ([]fyne.URI, error) {
+	if !m.Options().FolderMosaic {
+		return nil, errFolderMosaicSkipped
+	}
+	entries, err := m.folderMosaicEntries(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var children []fyne.URI
+	for _, e := range entries {
+		if len(children) == folderMosaicMaxAttempts {
+			break
+		}
+		name := e.Name()
+		if e.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		child := storage.NewFileURI(filepath.Join(path, name))
+		if ft := m.detectFileType(child); ft.isImage() || ft.isVideo() {
+			children = append(children, child)
+		}
+	}
+	if len(children) == 0 {
+		return nil, errFolderMosaicSkipped
+	}
+	return children, nil
+}
+
+// generateFolderMosaic composes the thumbnails of the first images or videos
+// in a folder into a 2×2 grid, rendering them on the calling goroutine.
+func (m *ThumbnailManager) generateFolderMosaic(path string) (image.Image, error) {
+	children, err := m.folderMosaicCandidates(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var thumbs []image.Image
+	for _, child := range children {
+		if len(thumbs) == 4 {
+			break
+		}
+		thumb := m.LoadMemoryOnly(thumbnailID(child))
+		if thumb == nil {
+			thumb = m.loadFromDisk(child)
+		}
+		if thumb == nil {
+			thumb = m.renderThumbnail(child)
+		}
+		if thumb != nil && thumb.Image != nil {
+			thumbs = append(thumbs, thumb.Image)
+		}
+	}
+	if len(thumbs) == 0 {
+		return nil, errFolderMosaicSkipped
+	}
+	return composeMosaic(thumbs, 128), nil
+}
+
+// loadFolderMosaic returns the mosaic of a local folder from the caches, or
+// queues thumbnails of its children like any other and composes the mosaic
+// when they are done. The callback is not called if there is no mosaic.
+func (m *ThumbnailManager) loadFolderMosaic(uri fyne.URI, callback func(*canvas.Image)) {
+	key, err := m.folderCacheKey(uri.Path())
+	if err != nil {
+		return
+	}
+	// The mosaic is also kept under the folder's own id, for LoadMemoryOnly.
+	loaded := func(img *canvas.Image) {
+		m.cache.Store(thumbnailID(uri), img)
+		callback(img)
+	}
+	// The key covers the children, so a changed folder misses the memory cache too.
+	id := "mosaic:" + key
+	if cached, ok := m.cache.Load(id); ok {
+		loaded(cached.(*canvas.Image))
+		return
+	}
+	if img := m.loadCached(id, key); img != nil {
+		loaded(img)
+		return
+	}
+
+	children, err := m.folderMosaicCandidates(uri.Path())
+	if err != nil {
+		return
+	}
+	job := &mosaicJob{m: m, id: id, key: key, children: children, callback: loaded}
+	job.start()
+}
+
+// mosaicJob collects the child thumbnails of one folder mosaic. A child that
+// cannot be thumbnailed is replaced by the next candidate.
+type mosaicJob struct {
+	m        *ThumbnailManager
+	id, key  string
+	children []fyne.URI
+	callback func(*canvas.Image)
+
+	lock    sync.Mutex
+	thumbs  map[int]image.Image // by child index
+	next    int
+	pending int
+}
+
+func (j *mosaicJob) start() {
+	j.lock.Lock()
+	j.thumbs = make(map[int]image.Image, 4)
+	j.next = min(4, len(j.children))
+	j.pending = j.next
+	j.lock.Unlock()
+
+	for i := range j.next {
+		j.load(i)
+	}
+}
+
+func (j *mosaicJob) load(i int) {
+	j.m.load(j.children[i], func(img *canvas.Image) { j.done(i, img) }, func() { j.done(i, nil) })
+}
+
+func (j *mosaicJob) done(i int, img *canvas.Image) {
+	j.lock.Lock()
+	retry := -1
+	if img != nil && img.Image != nil {
+		j.thumbs[i] = img.Image
+		j.pending--
+	} else if j.next < len(j.children) {
+		retry = j.next
+		j.next++
+	} else {
+		j.pending--
+	}
+	finished := j.pending == 0
+	j.lock.Unlock()
+
+	if retry >= 0 {
+		j.load(retry)
+	} else if finished {
+		j.finish()
+	}
+}
+
+func (j *mosaicJob) finish() {
+	var thumbs []image.Image
+	for i := range j.children {
+		if img, ok := j.thumbs[i]; ok {
+			thumbs = append(thumbs, img)
+		}
+	}
+	if len(thumbs) == 0 {
+		return
+	}
+
+	dst := composeMosaic(thumbs, 128)
+	canvasImg := canvas.NewImageFromImage(dst)
+	canvasImg.FillMode = canvas.ImageFillContain
+	j.m.cache.Store(j.id, canvasImg)
+	j.m.saveToDisk(j.key, dst, mediaInfo{})
+	j.callback(canvasImg)
+}
+
+// composeMosaic draws up to four images into the cells of a size×size 2×2 grid.
+func composeMosaic(images []image.Image, size int) *image.RGBA {
+	const gap = 2
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	cell := (size - gap) / 2
+
+	for i, img := range images {
+		if i == 4 {
+			break
+		}
+		x := (i % 2) * (cell + gap)
+		y := (i / 2) * (cell + gap)
+		draw.ApproxBiLinear.Scale(dst, image.Rect(x, y, x+cell, y+cell), img, img.Bounds(), draw.Over, nil)
+	}
+	return dst
+}
@@ -0,0 +1,66 @@
+package dialog
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+)
+
+// dialogHooks are the callbacks set from Options. They are called on the
+// UI goroutine while the dialog is open.
+type dialogHooks struct {
+	onLocationChanged  func(fyne.ListableURI)
+	onSelectionChanged func([]fyne.URI)
+	onFilterChanged    func(storage.FileFilter)
+	onViewChanged      func(ViewLayout)
+
+	// The last values reported, so unchanged refreshes are not repeated.
+	lastLocation  string
+	lastSelection string
+}
+
+// reset forgets the values reported, so a dialog shown again reports its
+// folder and selection afresh.
+func (h *dialogHooks) reset() {
+	h.lastLocation, h.lastSelection = "", ""
+}
+
+// locationChanged reports dir if it differs from the last folder reported.
+func (h *dialogHooks) locationChanged(dir fyne.ListableURI) {
+	if h.onLocationChanged == nil || dir == nil || dir.String() == h.lastLocation {
+		return
+	}
+	h.lastLocation = dir.String()
+	h.onLocationChanged(dir)
+}
+
+// selectionChanged reports uris if they differ, in content or order, from
+// the last selection reported.
+func (h *dialogHooks) selectionChanged(uris []fyne.URI) {
+	if h.onSelectionChanged == nil {
+		return
+	}
+	keys := make([]string, len(uris))
+	for i, u := range uris {
+		keys[i] = u.String()
+	}
+	key := strings.Join(keys, "\n")
+	if key == h.lastSelection {
+		return
+	}
+	h.lastSelection = key
+	h.onSelectionChanged(uris)
+}
+
+func (h *dialogHooks) filterChanged(filter storage.FileFilter) {
+	if h.onFilterChanged != nil {
+		h.onFilterChanged(filter)
+	}
+}
+
+func (h *dialogHooks) viewChanged(view ViewLayout) {
+	if h.onViewChanged != nil {
+		h.onViewChanged(view)
+	}
+}
@@ -0,0 +1,58 @@
+package dialog
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lruCache keeps the limit most recently used values by key. The zero value
+// is ready to use once limit is set.
+type lruCache[V any] struct {
+	limit int
+
+	lock  sync.Mutex
+	items map[string]*list.Element
+	order list.List // front is the most recently used
+}
+
+type lruEntry[V any] struct {
+	key   string
+	value V
+}
+
+func (c *lruCache[V]) get(key string) (V, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry[V]).value, true
+}
+
+func (c *lruCache[V]) add(key string, value V) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.items == nil {
+		c.items = make(map[string]*list.Element)
+	}
+	if e, ok := c.items[key]; ok {
+		e.Value.(*lruEntry[V]).value = value
+		c.order.MoveToFront(e)
+		return
+	}
+	c.items[key] = c.order.PushFront(&lruEntry[V]{key: key, value: value})
+	for c.order.Len() > c.limit {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry[V]).key)
+	}
+}
+
+func (c *lruCache[V]) len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,257 @@
+package dialog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+// mediaInfo is the metadata gathered while generating a thumbnail.
+// It is cached next to the disk thumbnail as a JSON sidecar.
+type mediaInfo struct {
+	Width    int           `json:"width,omitempty"`
+	Height   int           `json:"height,omitempty"`
+	Duration time.Duration `json:"duration,omitempty"`
+	// Format is a short codec or image format tag such as "H264" or "PNG".
+	Format string `json:"format,omitempty"`
+
+	// Capture details from EXIF, or from container tags for videos.
+	Camera      string    `json:"camera,omitempty"`
+	Lens        string    `json:"lens,omitempty"`
+	Exposure    string    `json:"exposure,omitempty"`
+	Aperture    float64   `json:"aperture,omitempty"`
+	ISO         int       `json:"iso,omitempty"`
+	FocalLength float64   `json:"focal_length,omitempty"`
+	Taken       time.Time `json:"taken,omitzero"`
+	GPS         bool      `json:"gps,omitempty"`
+}
+
+// ffmpeg prints "Duration: HH:MM:SS.mm" and stream details like
+// "Stream #0:0(und): Video: h264 (High) (avc1 / 0x31637661), yuv420p, 1920x1080 [SAR 1:1 DAR 16:9], ..."
+var (
+	durationRegexp   = regexp.MustCompile(`Duration: (\d{2}):(\d{2}):(\d{2})\.(\d{2})`)
+	videoCodecRegexp = regexp.MustCompile(`Stream #\d+:\d+.*?: Video: (\w+)`)
+	videoSizeRegexp  = regexp.MustCompile(`Stream #\d+:\d+.*?: Video: .*?, (\d{2,5})x(\d{2,5})`)
+)
+
+// parseFFmpegInfo extracts duration, codec and resolution from the output of `ffmpeg -i`.
+func parseFFmpegInfo(out string) (mediaInfo, error) {
+	var info mediaInfo
+
+	matches := durationRegexp.FindStringSubmatch(out)
+	if len(matches) < 5 {
+		return info, fmt.Errorf("could not find duration in output")
+	}
+
+	hours := 0
+	minutes := 0
+	seconds := 0
+	centiseconds := 0
+
+	fmt.Sscanf(matches[1], "%d", &hours)
+	fmt.Sscanf(matches[2], "%d", &minutes)
+	fmt.Sscanf(matches[3], "%d", &seconds)
+	fmt.Sscanf(matches[4], "%d", &centiseconds)
+
+	info.Duration = time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute +
+		time.Duration(seconds)*time.Second +
+		time.Duration(centiseconds*10)*time.Millisecond
+
+	if m := videoCodecRegexp.FindStringSubmatch(out); len(m) == 2 {
+		info.Format = strings.ToUpper(m[1])
+	}
+	if m := videoSizeRegexp.FindStringSubmatch(out); len(m) == 3 {
+		info.Width, _ = strconv.Atoi(m[1])
+		info.Height, _ = strconv.Atoi(m[2])
+	}
+	return info, nil
+}
+
+// ffprobeOutput is the subset of `ffprobe -print_format json` output we use.
+type ffprobeOutput struct {
+	Streams []struct {
+		CodecType string `json:"codec_type"`
+		CodecName string `json:"codec_name"`
+		Width     int    `json:"width"`
+		Height    int    `json:"height"`
+	} `json:"streams"`
+	Format struct {
+		Duration string            `json:"duration"`
+		Tags     map[string]string `json:"tags"`
+	} `json:"format"`
+}
+
+// parseFFprobeInfo extracts duration, codec, resolution, creation time and
+// location presence from ffprobe's JSON output.
+func parseFFprobeInfo(data []byte) (mediaInfo, error) {
+	var info mediaInfo
+	var out ffprobeOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return info, err
+	}
+
+	seconds, err := strconv.ParseFloat(out.Format.Duration, 64)
+	if err != nil {
+		return info, fmt.Errorf("could not find duration in output")
+	}
+	info.Duration = time.Duration(seconds * float64(time.Second))
+
+	for _, s := range out.Streams {
+		if s.CodecType == "video" {
+			info.Format = strings.ToUpper(s.CodecName)
+			info.Width, info.Height = s.Width, s.Height
+			break
+		}
+	}
+	for key, value := range out.Format.Tags {
+		switch strings.ToLower(key) {
+		case "creation_time", "com.apple.quicktime.creationdate":
+			if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+				info.Taken = t.Local()
+			}
+		case "location", "com.apple.quicktime.location.iso6709":
+			info.GPS = value != ""
+		case "com.apple.quicktime.model":
+			info.Camera = value
+		}
+	}
+	return info, nil
+}
+
+// probeVideo reads a video's duration, codec and size, preferring ffprobe next
+// to the configured ffmpeg and falling back to parsing `ffmpeg -i`.
+func (m *ThumbnailManager) probeVideo(uri fyne.URI) (mediaInfo, error) {
+	if probe := m.ffprobePath(); probe != "" {
+		var stdout bytes.Buffer
+		args := []string{"-v", "quiet", "-print_format", "json", "-show_format", "-show_streams"}
+		if err := m.runFFTool(probe, uri, args, nil, &stdout, nil); err == nil {
+			if info, err := parseFFprobeInfo(stdout.Bytes()); err == nil {
+				return info, nil
+			}
+		}
+	}
+
+	// ffmpeg -i <file> 2>&1 | grep "Duration"
+	// ffmpeg prints to stderr
+	var stderr bytes.Buffer
+	// Run usually fails because no output file is specified, but we get the info
+	_ = m.runFFmpeg(uri, nil, nil, nil, &stderr)
+
+	return parseFFmpegInfo(stderr.String())
+}
+
+// ffprobePath returns the ffprobe binary expected next to ffmpeg, or "" if there is none.
+func (m *ThumbnailManager) ffprobePath() string {
+	if m.ffmpegPath == "" {
+		return ""
+	}
+	dir, name := filepath.Split(m.ffmpegPath)
+	probe := strings.Replace(name, "ffmpeg", "ffprobe", 1)
+	if probe == name {
+		return ""
+	}
+	path, err := exec.LookPath(filepath.Join(dir, probe))
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+func imageMediaInfo(img image.Image, format string) mediaInfo {
+	b := img.Bounds()
+	return mediaInfo{Width: b.Dx(), Height: b.Dy(), Format: strings.ToUpper(format)}
+}
+
+func readMediaInfo(path string) (mediaInfo, error) {
+	var info mediaInfo
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return info, err
+	}
+	err = json.Unmarshal(data, &info)
+	return info, err
+}
+
+func writeMediaInfo(path string, info mediaInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// resolution returns "W×H", or "" if the size is unknown.
+func (i mediaInfo) resolution() string {
+	if i.Width <= 0 || i.Height <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d×%d", i.Width, i.Height)
+}
+
+// exposureSummary combines aperture, shutter speed, ISO and focal length,
+// e.g. "f/2.8 · 1/250 s · ISO 400 · 23 mm".
+func (i mediaInfo) exposureSummary() string {
+	var parts []string
+	if i.Aperture > 0 {
+		parts = append(parts, "f/"+strconv.FormatFloat(i.Aperture, 'f', -1, 64))
+	}
+	if i.Exposure != "" {
+		parts = append(parts, i.Exposure)
+	}
+	if i.ISO > 0 {
+		parts = append(parts, fmt.Sprintf("ISO %d", i.ISO))
+	}
+	if i.FocalLength > 0 {
+		parts = append(parts, strconv.FormatFloat(i.FocalLength, 'f', -1, 64)+" mm")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// summary is a one line description used in the list view's detail column.
+func (i mediaInfo) summary() string {
+	var parts []string
+	add := func(s string) {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	if i.Duration > 0 {
+		add(formatMediaDuration(i.Duration))
+		add(i.Format)
+		add(i.resolution())
+	} else {
+		add(i.Camera)
+		add(i.exposureSummary())
+		if i.Camera == "" {
+			add(i.resolution())
+		}
+	}
+	if !i.Taken.IsZero() {
+		add(i.Taken.Format("2006-01-02"))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// formatMediaDuration renders d as "m:ss" or "h:mm:ss".
+func formatMediaDuration(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	total := int(d.Round(time.Second).Seconds())
+	h, m, s := total/3600, (total/60)%60, total%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
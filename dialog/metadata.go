@@ -0,0 +1,212 @@
+package dialog
+
+import (
+	"image"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+)
+
+// metadataSem bounds concurrent background metadata reads.
+var metadataSem = make(chan struct{}, 4)
+
+// readImageEXIF merges the EXIF metadata of the image at uri into info.
+func (m *ThumbnailManager) readImageEXIF(uri fyne.URI, info *mediaInfo) {
+	var r io.ReadCloser
+	var err error
+	if uri.Scheme() == "file" {
+		r, err = os.Open(uri.Path())
+	} else {
+		r, err = storage.Reader(uri)
+	}
+	if err != nil {
+		return
+	}
+	defer r.Close()
+
+	_ = readEXIF(r, info)
+}
+
+// metadata returns the media metadata of uri without rendering a thumbnail.
+// Images have their header and EXIF read, videos are probed with FFmpeg.
+// Results are cached; the call may block and should be made off the UI thread.
+func (m *ThumbnailManager) metadata(uri fyne.URI) (mediaInfo, bool) {
+	id := thumbnailID(uri)
+	if info, ok := m.meta.Load(id); ok {
+		return info.(mediaInfo), true
+	}
+
+	var info mediaInfo
+	ft := m.detectFileType(uri)
+	switch {
+	case ft.isImage():
+		config, format, err := decodeImageConfig(uri)
+		if err != nil {
+			return info, false
+		}
+		info = mediaInfo{Width: config.Width, Height: config.Height, Format: strings.ToUpper(format)}
+		m.readImageEXIF(uri, &info)
+	case ft.isVideo() && m.ffmpegPath != "":
+		probed, err := m.probeVideo(uri)
+		if err != nil {
+			return info, false
+		}
+		info = probed
+	case ft.isAudio():
+		info.Format = audioFormatNames[ft.mime]
+	default:
+		return info, false
+	}
+
+	m.meta.Store(id, info)
+	return info, true
+}
+
+// LoadMetadata reads the media metadata of uri in the background and calls
+// callback with the result. The callback is not called if nothing could be read.
+func (m *ThumbnailManager) LoadMetadata(uri fyne.URI, callback func(mediaInfo)) {
+	if info, ok := m.meta.Load(thumbnailID(uri)); ok {
+		callback(info.(mediaInfo))
+		return
+	}
+
+	go func() {
+		metadataSem <- struct{}{}
+		info, ok := m.metadata(uri)
+		<-metadataSem
+		if ok {
+			callback(info)
+		}
+	}()
+}
+
+func decodeImageConfig(uri fyne.URI) (image.Config, string, error) {
+	var r io.ReadCloser
+	var err error
+	if uri.Scheme() == "file" {
+		r, err = os.Open(uri.Path())
+	} else {
+		r, err = storage.Reader(uri)
+	}
+	if err != nil {
+		return image.Config{}, "", err
+	}
+	defer r.Close()
+
+	return image.DecodeConfig(r)
+}
+
+// metadataFilter is a "key:value" term of a search query, e.g. "camera:X100V".
+type metadataFilter struct {
+	key, value string
+}
+
+// metadataFilterKeys lists the supported search keys and whether they apply
+// to videos; all apply to images.
+var metadataFilterKeys = map[string]bool{
+	"camera": true,
+	"lens":   false,
+	"iso":    false,
+	"taken":  true,
+	"codec":  true,
+	"res":    true,
+	"gps":    true,
+}
+
+// parseSearchQuery splits a search query into the plain name text and the
+// metadata filters it contains. Unknown keys are kept as name text.
+func parseSearchQuery(query string) (string, []metadataFilter) {
+	var text []string
+	var filters []metadataFilter
+	for _, word := range strings.Fields(query) {
+		key, value, ok := strings.Cut(word, ":")
+		key = strings.ToLower(key)
+		if _, known := metadataFilterKeys[key]; !ok || !known || value == "" {
+			text = append(text, word)
+			continue
+		}
+		filters = append(filters, metadataFilter{key: key, value: strings.ToLower(value)})
+	}
+	return strings.Join(text, " "), filters
+}
+
+// appliesTo reports whether the filter can match a file of type ft at all.
+func (f metadataFilter) appliesTo(ft fileType) bool {
+	if ft.isVideo() {
+		return metadataFilterKeys[f.key]
+	}
+	return ft.isImage() && f.key != "codec"
+}
+
+// matches reports whether info satisfies the filter.
+func (f metadataFilter) matches(info mediaInfo) bool {
+	switch f.key {
+	case "camera":
+		return strings.Contains(strings.ToLower(info.Camera), f.value)
+	case "lens":
+		return strings.Contains(strings.ToLower(info.Lens), f.value)
+	case "iso":
+		iso, err := strconv.Atoi(f.value)
+		return err == nil && info.ISO == iso
+	case "taken":
+		return !info.Taken.IsZero() && strings.HasPrefix(formatPreviewTime(info.Taken), f.value)
+	case "codec":
+		return strings.EqualFold(info.Format, f.value)
+	case "res":
+		return strings.EqualFold(strings.ReplaceAll(info.resolution(), "×", "x"), strings.ReplaceAll(f.value, "×", "x"))
+	case "gps":
+		switch f.value {
+		case "yes", "true", "1":
+			return info.GPS
+		case "no", "false", "0":
+			return !info.GPS
+		}
+	}
+	return false
+}
+
+// matchesMetadata reports whether u satisfies all filters. Files the filters
+// cannot apply to, or whose metadata cannot be read, do not match. Reading
+// the metadata may block; see matchesCachedMetadata.
+func (m *ThumbnailManager) matchesMetadata(u fyne.URI, filters []metadataFilter) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	return matchesMetadataFilters(m.detectFileType(u), filters, func() (mediaInfo, bool) { return m.metadata(u) })
+}
+
+// matchesCachedMetadata is matchesMetadata for the UI thread. Only metadata
+// that has already been read is used; other files do not match.
+func (m *ThumbnailManager) matchesCachedMetadata(u fyne.URI, filters []metadataFilter) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	return matchesMetadataFilters(m.itemFileType(u), filters, func() (mediaInfo, bool) {
+		if info, ok := m.meta.Load(thumbnailID(u)); ok {
+			return info.(mediaInfo), true
+		}
+		return mediaInfo{}, false
+	})
+}
+
+func matchesMetadataFilters(ft fileType, filters []metadataFilter, lookup func() (mediaInfo, bool)) bool {
+	for _, f := range filters {
+		if !f.appliesTo(ft) {
+			return false
+		}
+	}
+	info, ok := lookup()
+	if !ok {
+		return false
+	}
+	for _, f := range filters {
+		if !f.matches(info) {
+			return false
+		}
+	}
+	return true
+}
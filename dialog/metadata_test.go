@@ -0,0 +1,115 @@
+package dialog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFFprobeInfo(t *testing.T) {
+	out := []byte(`{
+		"streams": [
+			{"codec_type": "audio", "codec_name": "aac"},
+			{"codec_type": "video", "codec_name": "hevc", "width": 3840, "height": 2160}
+		],
+		"format": {
+			"duration": "12.500000",
+			"tags": {"creation_time": "2025-06-14T09:30:00.000000Z", "location": "+51.5000-000.1200/"}
+		}
+	}`)
+	info, err := parseFFprobeInfo(out)
+	if err != nil {
+		t.Fatalf("parseFFprobeInfo failed: %v", err)
+	}
+	if info.Duration != 12500*time.Millisecond || info.Format != "HEVC" || info.resolution() != "3840×2160" {
+		t.Errorf("unexpected stream info %+v", info)
+	}
+	if !info.Taken.Equal(time.Date(2025, 6, 14, 9, 30, 0, 0, time.UTC)) || !info.GPS {
+		t.Errorf("unexpected tags taken=%v gps=%v", info.Taken, info.GPS)
+	}
+
+	if _, err := parseFFprobeInfo([]byte(`{"format": {}}`)); err == nil {
+		t.Error("expected an error without a duration")
+	}
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	text, filters := parseSearchQuery("holiday Camera:X100V taken:2025-06 note:x iso:")
+	if text != "holiday note:x iso:" {
+		t.Errorf("text = %q", text)
+	}
+	want := []metadataFilter{{"camera", "x100v"}, {"taken", "2025-06"}}
+	if len(filters) != len(want) {
+		t.Fatalf("filters = %v, want %v", filters, want)
+	}
+	for i := range want {
+		if filters[i] != want[i] {
+			t.Errorf("filter %d = %v, want %v", i, filters[i], want[i])
+		}
+	}
+}
+
+func TestMetadataFilter_Matches(t *testing.T) {
+	photo := mediaInfo{
+		Width: 6240, Height: 4160, Camera: "FUJIFILM X100V", Lens: "XF23mmF2", ISO: 400,
+		Taken: time.Date(2025, 6, 14, 9, 30, 0, 0, time.Local), GPS: true,
+	}
+	video := mediaInfo{Width: 1920, Height: 1080, Duration: time.Minute, Format: "H264"}
+
+	cases := []struct {
+		query string
+		info  mediaInfo
+		want  bool
+	}{
+		{"camera:x100v", photo, true},
+		{"camera:canon", photo, false},
+		{"lens:23mm", photo, true},
+		{"iso:400", photo, true},
+		{"iso:40", photo, false},
+		{"taken:2025-06", photo, true},
+		{"taken:2025-06-14", photo, true},
+		{"taken:2024", photo, false},
+		{"taken:2025", video, false},
+		{"gps:yes", photo, true},
+		{"gps:no", video, true},
+		{"res:6240x4160", photo, true},
+		{"res:1920×1080", video, true},
+		{"codec:h264", video, true},
+		{"codec:hevc", video, false},
+	}
+	for _, c := range cases {
+		_, filters := parseSearchQuery(c.query)
+		if len(filters) != 1 {
+			t.Fatalf("%q: expected one filter, got %v", c.query, filters)
+		}
+		if got := filters[0].matches(c.info); got != c.want {
+			t.Errorf("%q matches = %v, want %v", c.query, got, c.want)
+		}
+	}
+}
+
+func TestMetadataFilter_AppliesTo(t *testing.T) {
+	image := fileType{mime: "image/jpeg"}
+	video := fileType{mime: "video/mp4"}
+	text := fileType{mime: "text/plain"}
+
+	if !(metadataFilter{key: "camera"}).appliesTo(image) || (metadataFilter{key: "codec"}).appliesTo(image) {
+		t.Error("image applicability wrong")
+	}
+	if !(metadataFilter{key: "codec"}).appliesTo(video) || (metadataFilter{key: "iso"}).appliesTo(video) {
+		t.Error("video applicability wrong")
+	}
+	if (metadataFilter{key: "taken"}).appliesTo(text) {
+		t.Error("filters should not apply to text files")
+	}
+}
+
+func TestMediaInfo_Summary(t *testing.T) {
+	photo := mediaInfo{Width: 10, Height: 5, Camera: "X100V", Aperture: 2, Taken: time.Date(2025, 6, 14, 0, 0, 0, 0, time.Local)}
+	if got := photo.summary(); got != "X100V · f/2 · 2025-06-14" {
+		t.Errorf("photo summary = %q", got)
+	}
+	video := mediaInfo{Width: 1920, Height: 1080, Duration: 90 * time.Second, Format: "H264"}
+	if got := video.summary(); got != formatMediaDuration(video.Duration)+" · H264 · 1920×1080" {
+		t.Errorf("video summary = %q", got)
+	}
+}
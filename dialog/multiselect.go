@@ -1,10 +1,12 @@
 package dialog
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -24,23 +26,37 @@ const (
 	openDialogModeFile openDialogMode = iota
 	openDialogModeFolder
 	openDialogModeSave
+	openDialogModeFileOrFolder
 )
 
 // ShowFileOpen creates and shows a file dialog allowing the user to choose
-// one or more files to open.
+// one or more files to open. If some files cannot be opened, err is a
+// *SelectionError and readers holds the ones that did open; the callback
+// must close them even when err is not nil.
 func ShowFileOpen(callback func(readers []fyne.URIReadCloser, err error), parent fyne.Window, allowMultiple bool) {
 	d := NewFileOpen(callback, parent, allowMultiple)
 	d.Show()
 }
 
-// NewFileOpen creates a file dialog allowing the user to choose one or more files to open.
+// NewFileOpen creates a file dialog allowing the user to choose one or more
+// files to open. The callback must close the readers it is given, even when
+// err is a *SelectionError; see ShowFileOpen.
 func NewFileOpen(callback func(readers []fyne.URIReadCloser, err error), parent fyne.Window, allowMultiple bool) dialog.Dialog {
-	d := newDialogBase(parent)
-	d.mode = openDialogModeFile
-	d.callback = callback
-	d.allowMultiple = allowMultiple
-	d.loadPrefs()
-	return d
+	return NewFileOpenWithOptions(callback, parent, Options{AllowMultiple: allowMultiple})
+}
+
+// ShowFileOpenURIs creates and shows a file dialog allowing the user to choose
+// one or more files. The URIs are returned without being opened, so the app
+// can open them lazily.
+func ShowFileOpenURIs(callback func(uris []fyne.URI, err error), parent fyne.Window, allowMultiple bool) {
+	d := NewFileOpenURIs(callback, parent, allowMultiple)
+	d.Show()
+}
+
+// NewFileOpenURIs creates a file dialog allowing the user to choose one or more
+// files, returning their URIs rather than open readers.
+func NewFileOpenURIs(callback func(uris []fyne.URI, err error), parent fyne.Window, allowMultiple bool) dialog.Dialog {
+	return NewFileOpenURIsWithOptions(callback, parent, Options{AllowMultiple: allowMultiple})
 }
 
 // ShowFileSave creates and shows a file dialog allowing the user to choose a file path for saving.
@@ -51,12 +67,7 @@ func ShowFileSave(callback func(writer fyne.URIWriteCloser, err error), parent f
 
 // NewFileSave creates a file dialog allowing the user to choose a file path for saving.
 func NewFileSave(callback func(writer fyne.URIWriteCloser, err error), parent fyne.Window) dialog.Dialog {
-	d := newDialogBase(parent)
-	d.mode = openDialogModeSave
-	d.saveCallback = callback
-	d.allowMultiple = false
-	d.loadPrefs()
-	return d
+	return NewFileSaveWithOptions(callback, parent, Options{})
 }
 
 // ShowFolderOpen creates and shows a folder dialog allowing the user to choose a folder.
@@ -67,12 +78,32 @@ func ShowFolderOpen(callback func(dir fyne.ListableURI, err error), parent fyne.
 
 // NewFolderOpen creates a folder dialog allowing the user to choose a single folder.
 func NewFolderOpen(callback func(dir fyne.ListableURI, err error), parent fyne.Window) dialog.Dialog {
-	d := newDialogBase(parent)
-	d.mode = openDialogModeFolder
-	d.folderCallback = callback
-	d.allowMultiple = false
-	d.loadPrefs()
-	return d
+	return NewFolderOpenWithOptions(callback, parent, Options{})
+}
+
+// ShowFoldersOpen creates and shows a folder dialog allowing the user to choose one or more folders.
+func ShowFoldersOpen(callback func(dirs []fyne.ListableURI, err error), parent fyne.Window) {
+	d := NewFoldersOpen(callback, parent)
+	d.Show()
+}
+
+// NewFoldersOpen creates a folder dialog allowing the user to choose one or more folders.
+// Opening with nothing selected chooses the current folder.
+func NewFoldersOpen(callback func(dirs []fyne.ListableURI, err error), parent fyne.Window) dialog.Dialog {
+	return NewFoldersOpenWithOptions(callback, parent, Options{})
+}
+
+// ShowFileOrFolderOpen creates and shows a dialog allowing the user to choose
+// files and folders together. Folders are returned as fyne.ListableURI.
+func ShowFileOrFolderOpen(callback func(uris []fyne.URI, err error), parent fyne.Window, allowMultiple bool) {
+	d := NewFileOrFolderOpen(callback, parent, allowMultiple)
+	d.Show()
+}
+
+// NewFileOrFolderOpen creates a dialog allowing the user to choose files and folders together.
+// Double-clicking a folder still navigates into it.
+func NewFileOrFolderOpen(callback func(uris []fyne.URI, err error), parent fyne.Window, allowMultiple bool) dialog.Dialog {
+	return NewFileOrFolderOpenWithOptions(callback, parent, Options{AllowMultiple: allowMultiple})
 }
 
 func newDialogBase(parent fyne.Window) *fileDialog {
@@ -83,6 +114,8 @@ func newDialogBase(parent fyne.Window) *fileDialog {
 		view:      defaultView, // Will be loaded from prefs
 		zoomLevel: defaultZoomLevelIndex,
 		anchor:    -1,
+
+		previewWidth: defaultPreviewWidth,
 	}
 	d.confirmOverwrite = d.confirmOverwriteDialog
 	return d
@@ -91,23 +124,42 @@ func newDialogBase(parent fyne.Window) *fileDialog {
 type fileDialog struct {
 	callback       func([]fyne.URIReadCloser, error)
 	folderCallback func(fyne.ListableURI, error)
-	saveCallback   func(fyne.URIWriteCloser, error)
-	parent         fyne.Window
-	dir            fyne.ListableURI
+	// foldersCallback replaces folderCallback when several folders may be chosen.
+	foldersCallback func([]fyne.ListableURI, error)
+	saveCallback    func(fyne.URIWriteCloser, error)
+	uriCallback     func([]fyne.URI, error)
+	parent          fyne.Window
+	dir             fyne.ListableURI
 
 	selected map[string]fyne.URI
+	// order holds the selected keys in the order they were selected, or as
+	// rearranged in the selection tray when orderCustomized is set.
+	order           []string
+	orderCustomized bool
+	selectionOrder  SelectionOrder
+	// persistentSelection keeps the selection when moving between folders.
+	persistentSelection bool
+	constraints         SelectionConstraints
+	// selectionNotice explains why the last selection change was refused.
+	selectionNotice string
+	// fileSizes caches file sizes for MaxTotalBytes, keyed by URI.
+	fileSizes map[string]int64
 
 	// Components
 	sidebar    *sidebar
 	fileList   *fileList
 	breadcrumb *breadcrumb
+	preview    *previewPane
+	tray       *selectionTray
 
 	// UI
 	win      *widget.PopUp
 	fileName *widget.Label
-	saveName *widget.Entry
-	open     *widget.Button
-	dismiss  *widget.Button
+	// selectionProblem shows why the selection cannot be confirmed.
+	selectionProblem *widget.Label
+	saveName         *widget.Entry
+	open             *widget.Button
+	dismiss          *widget.Button
 
 	view       ViewLayout
 	showHidden bool
@@ -124,46 +176,96 @@ type fileDialog struct {
 	originalOnTypedRune func(rune)
 	originalOnTypedKey  func(*fyne.KeyEvent)
 	activeMenu          *widget.PopUp
+	quickLook           *quickLook
+
+	// cancelDetection stops sniffing the files of the previous listing.
+	cancelDetection context.CancelFunc
+
+	// busy spins over the file list while archives are being opened.
+	busy           *widget.Activity
+	openingArchive int
 
 	zoomInBtn  *widget.Button
 	zoomOutBtn *widget.Button
 
+	// Preview pane; previewWidth is its share of the file area.
+	previewSplit   *container.Split
+	previewToggle  *widget.Button
+	previewVisible bool
+	previewWidth   float64
+
 	mode openDialogMode
 
+	// Set from Options
+	title         string
+	confirmLabel  string
+	dismissLabel  string
+	sortOrder     FileSortOrder
+	preselect     []fyne.URI
+	thumbs        *ThumbnailManager
+	prefNamespace string
+	hooks         dialogHooks
+	// embedded is set when the UI is shown by a FileBrowser rather than a popup.
+	embedded bool
+	browser  fyne.CanvasObject
+	// keysAttached is set while a browser's key hooks are on its window.
+	keysAttached bool
+	// nativeWindow shows the dialog in window, with blocker over the parent.
+	nativeWindow bool
+	window       fyne.Window
+	blocker      *widget.PopUp
+
 	defaultSaveName  string
 	confirmOverwrite func(target fyne.URI, confirm func(bool))
 }
 
 func (f *fileDialog) Show() {
+	// Showing again would replace the open window or popup and leak it.
+	if f.isShown() {
+		return
+	}
 	if fileOpenOSOverride(f) {
 		return
 	}
+	f.hooks.reset()
 
-	content := f.makeUI()
-	f.win = widget.NewModalPopUp(content, f.parent.Canvas())
-	f.win.Resize(fyne.NewSize(1000, 700))
+	if f.nativeWindow && !fyne.CurrentDevice().IsMobile() {
+		f.showWindow()
+	} else {
+		content := f.makeUI()
+		f.win = widget.NewModalPopUp(content, f.parent.Canvas())
+		f.win.Resize(fyne.NewSize(1000, 700))
 
-	f.win.Show()
+		f.win.Show()
+	}
 
 	// Intercept keys for Type-to-Search
 	// NOTE: We register hooks AFTER Show() to capture any hooks that ModalPopUp might set (e.g. for closing on Escape)
-	f.originalOnTypedRune = f.parent.Canvas().OnTypedRune()
-	f.parent.Canvas().SetOnTypedRune(f.typedRuneHook)
-	f.originalOnTypedKey = f.parent.Canvas().OnTypedKey()
-	f.parent.Canvas().SetOnTypedKey(f.typedKeyHook)
+	c := f.host().Canvas()
+	f.originalOnTypedRune = c.OnTypedRune()
+	c.SetOnTypedRune(f.typedRuneHook)
+	f.originalOnTypedKey = c.OnTypedKey()
+	c.SetOnTypedKey(f.typedKeyHook)
 	f.refreshDir(f.dir)
+	f.applyPreselection()
 }
 
 func (f *fileDialog) Hide() {
+	f.savePreviewWidth()
+	if f.quickLook.visible() {
+		f.quickLook.hide()
+	}
+
 	// Restore original handler
-	if f.parent != nil && f.parent.Canvas() != nil {
-		f.parent.Canvas().SetOnTypedRune(f.originalOnTypedRune)
-		f.parent.Canvas().SetOnTypedKey(f.originalOnTypedKey)
+	if host := f.host(); host != nil && host.Canvas() != nil {
+		host.Canvas().SetOnTypedRune(f.originalOnTypedRune)
+		host.Canvas().SetOnTypedKey(f.originalOnTypedKey)
 	}
 
 	if f.win != nil {
 		f.win.Hide()
 	}
+	f.closeWindow()
 }
 
 func (f *fileDialog) Dismiss() {
@@ -187,6 +289,9 @@ func (f *fileDialog) Resize(size fyne.Size) {
 	if f.win != nil {
 		f.win.Resize(size)
 	}
+	if f.window != nil {
+		f.window.Resize(size)
+	}
 	f.DismissMenu()
 }
 
@@ -207,11 +312,50 @@ func (f *fileDialog) SetLocation(dir fyne.ListableURI) {
 	f.refreshDir(dir)
 }
 
+// openArchive lists the local archive u, which is item id of the listing.
+// Indexing reads the whole of a tar archive, so it is done in the background
+// with the busy indicator shown. The archive is only entered if the folder
+// has not changed meanwhile; one that cannot be read is opened as a file.
+func (f *fileDialog) openArchive(u fyne.URI, id int) {
+	from := f.dir
+	f.setOpeningArchive(1)
+	go func() {
+		root, err := NewArchiveURI(u)
+		fyne.Do(func() {
+			f.setOpeningArchive(-1)
+			if f.dir != from {
+				return
+			}
+			if err != nil {
+				f.Select(id)
+				f.OpenSelection()
+				return
+			}
+			f.SetLocation(root)
+		})
+	}()
+}
+
+func (f *fileDialog) setOpeningArchive(delta int) {
+	f.openingArchive += delta
+	if f.busy == nil {
+		return
+	}
+	if f.openingArchive > 0 {
+		f.busy.Show()
+		f.busy.Start()
+	} else {
+		f.busy.Stop()
+		f.busy.Hide()
+	}
+}
+
 func (f *fileDialog) SetView(view ViewLayout) {
 	f.DismissMenu()
 	f.view = view
-	fyne.CurrentApp().Preferences().SetInt(viewLayoutKey, int(view))
+	fyne.CurrentApp().Preferences().SetInt(f.prefKey(viewLayoutKey), int(view))
 	f.fileList.setView(view)
+	f.hooks.viewChanged(view)
 }
 
 func (f *fileDialog) GetView() ViewLayout {
@@ -237,7 +381,7 @@ func (f *fileDialog) setZoomLevel(level int) {
 	}
 
 	f.zoomLevel = level
-	fyne.CurrentApp().Preferences().SetInt(zoomLevelKey, f.zoomLevel)
+	fyne.CurrentApp().Preferences().SetInt(f.prefKey(zoomLevelKey), f.zoomLevel)
 
 	if f.fileList != nil {
 		f.fileList.setZoom(f.zoomScale())
@@ -262,6 +406,82 @@ func (f *fileDialog) updateZoomButtons() {
 	}
 }
 
+// setPreviewVisible shows or hides the preview pane and remembers the choice.
+func (f *fileDialog) setPreviewVisible(visible bool) {
+	f.savePreviewWidth()
+	f.previewVisible = visible
+	fyne.CurrentApp().Preferences().SetBool(f.prefKey(previewVisibleKey), visible)
+	f.updatePreviewVisibility()
+	f.updatePreview()
+}
+
+// updatePreviewVisibility applies the preference, hiding the pane while the dialog is too narrow.
+func (f *fileDialog) updatePreviewVisibility() {
+	if f.previewSplit == nil {
+		return
+	}
+	if f.previewToggle != nil {
+		if f.previewVisible {
+			f.previewToggle.SetIcon(theme.VisibilityOffIcon())
+		} else {
+			f.previewToggle.SetIcon(theme.VisibilityIcon())
+		}
+	}
+
+	width := f.previewSplit.Size().Width
+	if f.sidebar != nil {
+		width += f.sidebar.list.Size().Width
+	}
+	show := f.previewVisible && (width == 0 || width >= previewMinDialogWidth)
+	if show == f.preview.content.Visible() {
+		return
+	}
+	if show {
+		f.preview.content.Show()
+		f.previewSplit.SetOffset(1 - f.previewWidth)
+	} else {
+		f.savePreviewWidth()
+		f.preview.content.Hide()
+	}
+	f.previewSplit.Refresh()
+}
+
+// savePreviewWidth stores the pane width after the user dragged the divider.
+func (f *fileDialog) savePreviewWidth() {
+	if f.previewSplit == nil || f.preview == nil || !f.preview.content.Visible() {
+		return
+	}
+	width := 1 - f.previewSplit.Offset
+	if width <= 0 || width >= 1 || width == f.previewWidth {
+		return
+	}
+	f.previewWidth = width
+	fyne.CurrentApp().Preferences().SetFloat(f.prefKey(previewWidthKey), width)
+}
+
+// updatePreview shows the focused item: the selection anchor if it is selected,
+// otherwise the only selected item.
+func (f *fileDialog) updatePreview() {
+	if f.preview == nil || !f.preview.content.Visible() {
+		return
+	}
+	f.preview.update(f.focusedURI())
+}
+
+func (f *fileDialog) focusedURI() fyne.URI {
+	if f.fileList != nil && f.anchor >= 0 && f.anchor < len(f.fileList.filtered) {
+		if u := f.fileList.filtered[f.anchor]; f.IsSelected(u) {
+			return u
+		}
+	}
+	if len(f.selected) == 1 {
+		for _, u := range f.selected {
+			return u
+		}
+	}
+	return nil
+}
+
 func (f *fileDialog) IsMultiSelect() bool {
 	return f.allowMultiple
 }
@@ -274,24 +494,31 @@ func (f *fileDialog) isSaveMode() bool {
 	return f.mode == openDialogModeSave
 }
 
+func (f *fileDialog) isFileOrFolderMode() bool {
+	return f.mode == openDialogModeFileOrFolder
+}
+
 func (f *fileDialog) ShowMenu(menu *fyne.Menu, pos fyne.Position, obj fyne.CanvasObject) {
 	f.DismissMenu()
 
-	canvas := f.parent.Canvas()
-	if f.win != nil {
-		canvas = f.win.Canvas
-	}
-
 	m := widget.NewMenu(menu)
 	m.OnDismiss = f.DismissMenu
 
 	// Manually calculate absolute position since PopUp doesn't have ShowAtRelativePosition
 	absPos := fyne.CurrentApp().Driver().AbsolutePositionForObject(obj).Add(pos)
 
-	f.activeMenu = widget.NewPopUp(m, canvas)
+	f.activeMenu = widget.NewPopUp(m, f.canvas())
 	f.activeMenu.ShowAtPosition(absPos)
 }
 
+// canvas is where the dialog's menus and popups are shown.
+func (f *fileDialog) canvas() fyne.Canvas {
+	if f.win != nil {
+		return f.win.Canvas
+	}
+	return f.host().Canvas()
+}
+
 func (f *fileDialog) DismissMenu() {
 	if f.activeMenu != nil {
 		f.activeMenu.Hide()
@@ -303,9 +530,7 @@ func (f *fileDialog) Select(id int) {
 	if id < 0 || id >= len(f.fileList.filtered) {
 		return
 	}
-	uri := f.fileList.filtered[id]
-	f.selected = make(map[string]fyne.URI)
-	f.selected[uri.String()] = uri
+	f.setSelection([]fyne.URI{f.fileList.filtered[id]})
 	f.anchor = id
 	f.updateSaveNameFromSelection()
 	f.updateFooter()
@@ -316,14 +541,14 @@ func (f *fileDialog) SelectMultiple(ids []int) {
 	if !f.allowMultiple {
 		return
 	}
-	f.selected = make(map[string]fyne.URI)
+	var uris []fyne.URI
 	for _, id := range ids {
 		if id < 0 || id >= len(f.fileList.filtered) {
 			continue
 		}
-		uri := f.fileList.filtered[id]
-		f.selected[uri.String()] = uri
+		uris = append(uris, f.fileList.filtered[id])
 	}
+	f.setSelectionWithinLimits(uris)
 	if len(ids) > 0 {
 		f.anchor = ids[len(ids)-1]
 	}
@@ -342,9 +567,10 @@ func (f *fileDialog) ToggleSelection(id int) {
 	}
 	uri := f.fileList.filtered[id]
 	if f.IsSelected(uri) {
-		delete(f.selected, uri.String())
-	} else {
-		f.selected[uri.String()] = uri
+		f.removeSelected(uri.String())
+	} else if !f.trySelection(func() { f.addSelected(uri) }) {
+		f.updateFooter()
+		return
 	}
 	f.anchor = id
 	f.updateSaveNameFromSelection()
@@ -365,15 +591,21 @@ func (f *fileDialog) ExtendSelection(id int) {
 		f.anchor = 0
 	}
 
-	start, end := f.anchor, id
-	if start > end {
-		start, end = end, start
+	// Walk from the anchor so click order follows the direction of the range.
+	step := 1
+	if id < f.anchor {
+		step = -1
 	}
-
-	f.selected = make(map[string]fyne.URI)
-	for i := start; i <= end; i++ {
-		u := f.fileList.filtered[i]
-		f.selected[u.String()] = u
+	var uris []fyne.URI
+	for i := f.anchor; ; i += step {
+		uris = append(uris, f.fileList.filtered[i])
+		if i == id {
+			break
+		}
+	}
+	if !f.trySelection(func() { f.setSelection(uris) }) {
+		f.updateFooter()
+		return
 	}
 
 	f.updateSaveNameFromSelection()
@@ -387,6 +619,16 @@ func (f *fileDialog) IsSelected(uri fyne.URI) bool {
 }
 
 func (f *fileDialog) OpenSelection() {
+	if f.open == nil {
+		// A FileBrowser has no Open button; it reports the selection instead.
+		if f.embedded && f.uriCallback != nil && len(f.selected) > 0 {
+			f.uriCallback(f.selectedURIs(), nil)
+		}
+		return
+	}
+	if f.open.Disabled() {
+		return
+	}
 	f.open.OnTapped()
 }
 
@@ -410,9 +652,10 @@ func (f *fileDialog) CopyPath(uri fyne.URI) {
 
 func (f *fileDialog) SetFilter(filter storage.FileFilter) {
 	f.extensionFilter = filter
-	if f.win != nil {
+	if f.isShown() || f.embedded {
 		f.refreshDir(f.dir)
 	}
+	f.hooks.filterChanged(filter)
 }
 
 func (f *fileDialog) SetFileName(fileName string) {
@@ -423,15 +666,21 @@ func (f *fileDialog) SetFileName(fileName string) {
 }
 
 func (f *fileDialog) typedRuneHook(r rune) {
+	if f.quickLook.visible() {
+		if r == ' ' {
+			f.quickLook.hide()
+		}
+		return
+	}
 	if f.originalOnTypedRune != nil {
 		f.originalOnTypedRune(r)
 	}
 
-	if f.win == nil {
+	if !f.handlesKeys() {
 		return
 	}
 
-	focused := f.parent.Canvas().Focused()
+	focused := f.host().Canvas().Focused()
 
 	// If search entry is already focused, let standard handler work.
 	if focused == f.searchEntry {
@@ -459,18 +708,26 @@ func (f *fileDialog) typedRuneHook(r rune) {
 		return
 	}
 
+	if r == ' ' && f.openQuickLook() {
+		return
+	}
+
 	// Focus search and append the character
-	f.parent.Canvas().Focus(f.searchEntry)
+	f.host().Canvas().Focus(f.searchEntry)
 	f.searchEntry.SetText(f.searchEntry.Text + string(r))
 	f.searchEntry.CursorColumn = len(f.searchEntry.Text)
 	f.searchEntry.Refresh()
 }
 
 func (f *fileDialog) typedKeyHook(ev *fyne.KeyEvent) {
+	if ev != nil && f.quickLook.visible() {
+		f.quickLook.typedKey(ev)
+		return
+	}
 	if f.originalOnTypedKey != nil {
 		f.originalOnTypedKey(ev)
 	}
-	if f.win == nil || ev == nil {
+	if !f.handlesKeys() || ev == nil {
 		return
 	}
 
@@ -480,7 +737,7 @@ func (f *fileDialog) typedKeyHook(ev *fyne.KeyEvent) {
 
 	// Only trigger Open when focus is on the file list (or nothing focused).
 	// We must not interfere with dialogs/forms (e.g. New Folder) or text inputs.
-	focused := f.parent.Canvas().Focused()
+	focused := f.host().Canvas().Focused()
 	allowed := focused == nil
 	if !allowed && f.fileList != nil {
 		if focused == f.fileList.list || focused == f.fileList.grid {
@@ -491,7 +748,18 @@ func (f *fileDialog) typedKeyHook(ev *fyne.KeyEvent) {
 		return
 	}
 
+	if f.embedded {
+		f.openEmbeddedSelection()
+		return
+	}
+
 	if f.isFolderMode() {
+		if len(f.selected) > 1 && f.allowMultiple {
+			if !f.open.Disabled() {
+				f.open.OnTapped()
+			}
+			return
+		}
 		if len(f.selected) != 1 {
 			return
 		}
@@ -508,35 +776,50 @@ func (f *fileDialog) typedKeyHook(ev *fyne.KeyEvent) {
 	}
 }
 
+// openQuickLook shows the focused item in the Quick Look overlay.
+// Returns false if there is nothing to show.
+func (f *fileDialog) openQuickLook() bool {
+	u := f.focusedURI()
+	if u == nil || f.fileList == nil {
+		return false
+	}
+	if f.quickLook == nil || f.quickLook.canvas != f.host().Canvas() {
+		f.quickLook = newQuickLook(f)
+	}
+	f.DismissMenu()
+	f.quickLook.show(u)
+	return true
+}
+
 // Internal Logic
 
 func (f *fileDialog) makeUI() fyne.CanvasObject {
-	// Init sub-components
-	f.sidebar = newSidebar(f)
-	f.fileList = newFileList(f)
-	f.breadcrumb = newBreadcrumb(f)
-
-	f.fileList.setView(f.view)
-	f.fileList.setZoom(f.zoomScale())
-
 	// Footer
 	f.fileName = widget.NewLabel("")
 	f.fileName.Truncation = fyne.TextTruncateEllipsis
+	f.selectionProblem = widget.NewLabel("")
+	f.selectionProblem.Importance = widget.DangerImportance
+	f.selectionProblem.Hide()
 
 	confirmText := lang.L("Open")
 	if f.isSaveMode() {
 		confirmText = lang.L("Save")
 	}
+	if f.confirmLabel != "" {
+		confirmText = f.confirmLabel
+	}
 	f.open = widget.NewButton(confirmText, f.handleConfirmTapped)
 	f.open.Importance = widget.HighImportance
 	f.open.Disable()
 
-	f.dismiss = widget.NewButton(lang.L("Cancel"), func() {
+	dismissText := lang.L("Cancel")
+	if f.dismissLabel != "" {
+		dismissText = f.dismissLabel
+	}
+	f.dismiss = widget.NewButton(dismissText, func() {
 		f.Hide()
 		if f.isFolderMode() {
-			if f.folderCallback != nil {
-				f.folderCallback(nil, nil)
-			}
+			f.folderResult(nil, nil)
 			return
 		}
 		if f.isSaveMode() {
@@ -545,6 +828,10 @@ func (f *fileDialog) makeUI() fyne.CanvasObject {
 			}
 			return
 		}
+		if f.uriCallback != nil {
+			f.uriCallback(nil, nil)
+			return
+		}
 		if f.callback != nil {
 			f.callback(nil, nil)
 		}
@@ -562,7 +849,57 @@ func (f *fileDialog) makeUI() fyne.CanvasObject {
 		}
 		footerContent = f.saveName
 	}
-	footer := container.NewBorder(nil, nil, nil, container.NewHBox(f.dismiss, f.open), footerContent)
+	footer := fyne.CanvasObject(container.NewBorder(nil, nil, nil, container.NewHBox(f.selectionProblem, f.dismiss, f.open), footerContent))
+	if f.allowMultiple && !f.isSaveMode() {
+		f.tray = newSelectionTray(f)
+		footer = container.NewVBox(f.tray.content, footer)
+	}
+
+	titleLabel := widget.NewLabelWithStyle(f.titleText(), fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+
+	browser := f.makeBrowser(titleLabel)
+
+	root := f.newResizeRoot(container.NewBorder(nil, footer, nil, nil, browser))
+	f.updatePreviewVisibility()
+	f.updateFooter()
+	return root
+}
+
+// titleText is the dialog title, derived from its kind unless one was set.
+func (f *fileDialog) titleText() string {
+	titleText := lang.L("Open File")
+	if f.isFolderMode() {
+		titleText = lang.L("Open Folder")
+		if f.allowMultiple {
+			titleText = lang.L("Open Folders")
+		}
+	} else if f.isSaveMode() {
+		titleText = lang.L("Save File")
+	} else if f.isFileOrFolderMode() {
+		titleText = lang.L("Open File or Folder")
+		if f.allowMultiple {
+			titleText = lang.L("Open Files and Folders")
+		}
+	} else if f.allowMultiple {
+		titleText = lang.L("Open Files")
+	}
+	if f.title != "" {
+		titleText = f.title
+	}
+	return titleText
+}
+
+// makeBrowser builds the controls, sidebar, breadcrumb, file list and preview
+// shared by the dialogs and FileBrowser. leading is shown before the controls.
+func (f *fileDialog) makeBrowser(leading fyne.CanvasObject) fyne.CanvasObject {
+	// Init sub-components
+	f.sidebar = newSidebar(f)
+	f.fileList = newFileList(f)
+	f.fileList.thumbs = f.thumbs
+	f.breadcrumb = newBreadcrumb(f)
+
+	f.fileList.setView(f.view)
+	f.fileList.setZoom(f.zoomScale())
 
 	// Header / TopBar
 	f.searchEntry = widget.NewEntry()
@@ -600,19 +937,19 @@ func (f *fileDialog) makeUI() fyne.CanvasObject {
 			}
 			newFolderPath := filepath.Join(f.dir.Path(), newFolderEntry.Text)
 			if err := os.MkdirAll(newFolderPath, 0o750); err != nil {
-				dialog.ShowError(err, f.parent)
+				dialog.ShowError(err, f.host())
 			}
 			f.refreshDir(f.dir)
-		}, f.parent)
+		}, f.host())
 		d.Show()
-		f.parent.Canvas().Focus(newFolderEntry)
+		f.host().Canvas().Focus(newFolderEntry)
 	})
 
 	optionsBtn := widget.NewButtonWithIcon("", theme.SettingsIcon(), nil)
 	optionsBtn.OnTapped = func() {
 		hiddenFiles := widget.NewCheck(lang.L("Show Hidden Files"), func(changed bool) {
 			f.showHidden = changed
-			fyne.CurrentApp().Preferences().SetBool(showHiddenKey, changed)
+			fyne.CurrentApp().Preferences().SetBool(f.prefKey(showHiddenKey), changed)
 			f.refreshDir(f.dir)
 		})
 		hiddenFiles.Checked = f.showHidden
@@ -620,10 +957,24 @@ func (f *fileDialog) makeUI() fyne.CanvasObject {
 		content := container.NewVBox(
 			hiddenFiles,
 		)
-		pop := widget.NewPopUp(content, f.win.Canvas)
+		pop := widget.NewPopUp(content, f.canvas())
 		pop.ShowAtPosition(fyne.CurrentApp().Driver().AbsolutePositionForObject(optionsBtn).Add(fyne.NewPos(0, optionsBtn.Size().Height)))
 	}
 
+	// Bulk selection actions live in an overflow menu.
+	moreBtn := widget.NewButtonWithIcon("", theme.MoreHorizontalIcon(), nil)
+	moreBtn.OnTapped = func() {
+		var first fyne.URI
+		if uris := f.selectedURIs(); len(uris) > 0 {
+			first = uris[0]
+		}
+		menu := fyne.NewMenu("", selectionMenuItems(f, first)...)
+		f.ShowMenu(menu, fyne.NewPos(0, moreBtn.Size().Height), moreBtn)
+	}
+	if !f.allowMultiple || f.isSaveMode() {
+		moreBtn.Hide()
+	}
+
 	sortSelect := widget.NewSelect([]string{
 		lang.L("Name (A-Z)"),
 		lang.L("Name (Z-A)"),
@@ -644,7 +995,8 @@ func (f *fileDialog) makeUI() fyne.CanvasObject {
 		f.fileList.setSortOrder(order)
 	})
 	sortSelect.PlaceHolder = lang.L("Sort By")
-	sortSelect.SetSelected(lang.L("Name (A-Z)"))
+	sortSelect.SetSelected(sortLabel(f.sortOrder))
+	f.fileList.setSortOrder(f.sortOrder)
 
 	// Group controls into two rows.
 	searchWrapper := container.NewGridWrap(fyne.NewSize(220, 36), f.searchEntry)
@@ -656,20 +1008,13 @@ func (f *fileDialog) makeUI() fyne.CanvasObject {
 	})
 	f.updateZoomButtons()
 
-	controlsRow := container.NewHBox(searchWrapper, sortSelect, newFolderBtn, f.zoomOutBtn, f.zoomInBtn, viewToggle, optionsBtn)
+	f.previewToggle = widget.NewButtonWithIcon("", theme.VisibilityIcon(), func() {
+		f.setPreviewVisible(!f.previewVisible)
+	})
 
-	// Top Bar with Title and Controls
-	titleText := lang.L("Open File")
-	if f.isFolderMode() {
-		titleText = lang.L("Open Folder")
-	} else if f.isSaveMode() {
-		titleText = lang.L("Save File")
-	} else if f.allowMultiple {
-		titleText = lang.L("Open Files")
-	}
-	titleLabel := widget.NewLabelWithStyle(titleText, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	controlsRow := container.NewHBox(searchWrapper, sortSelect, newFolderBtn, f.zoomOutBtn, f.zoomInBtn, viewToggle, f.previewToggle, optionsBtn, moreBtn)
 
-	topBarContent := container.NewBorder(nil, nil, titleLabel, controlsRow, nil)
+	topBarContent := container.NewBorder(nil, nil, leading, controlsRow, nil)
 	topBarScroll := container.NewHScroll(topBarContent)
 	topBarScroll.Direction = container.ScrollHorizontalOnly
 
@@ -685,29 +1030,47 @@ func (f *fileDialog) makeUI() fyne.CanvasObject {
 		f.adjustZoom(steps)
 	})
 
+	f.busy = widget.NewActivity()
+	f.busy.Hide()
+
+	f.preview = newPreviewPane(f.thumbnails())
+	f.preview.content.Hide()
+	f.previewSplit = container.NewHSplit(
+		container.NewBorder(breadcrumbsArea, nil, nil, nil, container.NewStack(f.fileList.content, zoomOverlay, container.NewCenter(f.busy))),
+		f.preview.content,
+	)
+	f.previewSplit.SetOffset(1 - f.previewWidth)
+
 	split := container.NewHSplit(
 		container.NewPadded(f.sidebar.list),
-		container.NewBorder(breadcrumbsArea, nil, nil, nil, container.NewStack(f.fileList.content, zoomOverlay)),
+		f.previewSplit,
 	)
 	split.SetOffset(0.25)
 
+	return container.NewBorder(globalHeader, nil, nil, nil, split)
+}
+
+// newResizeRoot wraps content in a layout that reacts to being resized.
+func (f *fileDialog) newResizeRoot(content fyne.CanvasObject) fyne.CanvasObject {
 	// Wrap in a custom layout that detects resize
 	root := container.New(&resizeLayout{
 		internal: layout.NewStackLayout(),
 		onResize: func() {
 			f.DismissMenu()
+			f.updatePreviewVisibility()
+			f.updatePreview()
 			if f.fileList != nil {
 				f.fileList.onResize()
 			}
 		},
 		externalSize: func() fyne.Size {
-			if f.parent == nil || f.parent.Canvas() == nil {
+			host := f.host()
+			if host == nil || host.Canvas() == nil {
 				return fyne.Size{}
 			}
-			return f.parent.Canvas().Size()
+			return host.Canvas().Size()
 		},
-	}, container.NewBorder(globalHeader, footer, nil, nil, split))
-	f.updateFooter()
+	}, content)
 	return root
 }
 
@@ -802,7 +1165,10 @@ func (f *fileDialog) refreshDir(dir fyne.ListableURI) {
 	}
 
 	// Filter hidden & extensions
-	var filteredFiles []fyne.URI
+	f.stopTypeDetection()
+	thumbs := f.thumbnails()
+	_, mimeFilter := f.extensionFilter.(*storage.MimeTypeFileFilter)
+	var filteredFiles, undetected []fyne.URI
 	for _, file := range files {
 		if !f.showHidden && isHidden(file) {
 			continue
@@ -818,21 +1184,90 @@ func (f *fileDialog) refreshDir(dir fyne.ListableURI) {
 			continue
 		}
 
-		if f.extensionFilter == nil || f.extensionFilter.Matches(file) {
+		if mimeFilter && thumbs.needsDetection(file) {
+			undetected = append(undetected, file)
+		}
+		if f.extensionFilter == nil || thumbs.matchesFileFilter(f.extensionFilter, file) {
 			filteredFiles = append(filteredFiles, file)
 		}
 	}
 	files = filteredFiles
+	f.fileSizes = nil
 
 	if f.fileList != nil {
 		f.fileList.setFiles(files)
+		if len(undetected) > 0 {
+			f.filterDetectedTypes(undetected)
+		}
+	}
+	if !f.persistentSelection {
+		f.setSelection(nil)
 	}
-	f.selected = make(map[string]fyne.URI)
 	f.anchor = -1
+	f.hooks.locationChanged(dir)
 	f.updateFooter()
 }
 
+// filterDetectedTypes sniffs files that the MIME type filter matched by
+// extension in the background, then updates the listing with the types
+// found, as reading every file would hold up listing large folders.
+func (f *fileDialog) filterDetectedTypes(files []fyne.URI) {
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancelDetection = cancel
+	thumbs := f.thumbnails()
+	filter := f.extensionFilter
+	go func() {
+		changed := make(map[string]bool) // by URI, whether the file now matches
+		for _, file := range files {
+			if ctx.Err() != nil {
+				return
+			}
+			// The files were listed by extension, whatever has been detected since.
+			before := filter.Matches(file)
+			thumbs.detectFileType(file)
+			if after := thumbs.matchesFileFilter(filter, file); after != before {
+				changed[file.String()] = after
+			}
+		}
+		fyne.Do(func() {
+			if ctx.Err() != nil {
+				return
+			}
+			f.cancelDetection = nil
+			if len(changed) == 0 {
+				return
+			}
+			var listed []fyne.URI
+			for _, file := range f.fileList.files {
+				if matches, ok := changed[file.String()]; !ok || matches {
+					listed = append(listed, file)
+				}
+				delete(changed, file.String())
+			}
+			for _, file := range files {
+				if changed[file.String()] {
+					listed = append(listed, file)
+				}
+			}
+			f.fileList.setFiles(listed)
+			if f.searchEntry != nil && f.searchEntry.Text != "" {
+				f.fileList.setFilter(f.searchEntry.Text)
+			}
+		})
+	}()
+}
+
+func (f *fileDialog) stopTypeDetection() {
+	if f.cancelDetection != nil {
+		f.cancelDetection()
+		f.cancelDetection = nil
+	}
+}
+
 func (f *fileDialog) updateFooter() {
+	f.updatePreview()
+	uris := f.selectedURIs()
+	f.hooks.selectionChanged(uris)
 	if f.open == nil {
 		return
 	}
@@ -851,16 +1286,39 @@ func (f *fileDialog) updateFooter() {
 	}
 	var names []string
 	hasDir := false
-	for _, u := range f.selected {
+	if f.tray != nil {
+		f.tray.update(uris)
+	}
+	for _, u := range uris {
 		names = append(names, u.Name())
 		if isDir, _ := storage.CanList(u); isDir {
 			hasDir = true
 		}
 	}
-	f.fileName.SetText(strings.Join(names, ", "))
+	if f.isFolderMode() && len(f.selected) > 1 {
+		f.fileName.SetText(fmt.Sprintf(lang.L("%d folders selected"), len(f.selected)) + ": " + strings.Join(names, ", "))
+	} else {
+		f.fileName.SetText(strings.Join(names, ", "))
+	}
 
+	f.showSelectionProblem("")
 	if f.isFolderMode() {
-		f.open.Enable()
+		// With nothing selected the current folder is chosen.
+		targets := uris
+		if len(targets) == 0 && f.dir != nil {
+			targets = []fyne.URI{f.dir}
+		}
+		f.enableIfAllowed(targets)
+		return
+	}
+
+	// Files and folders can be mixed freely
+	if f.isFileOrFolderMode() {
+		if len(f.selected) == 0 {
+			f.open.Disable()
+		} else {
+			f.enableIfAllowed(uris)
+		}
 		return
 	}
 
@@ -869,25 +1327,52 @@ func (f *fileDialog) updateFooter() {
 		f.open.Disable()
 	} else if len(f.selected) > 1 && hasDir {
 		f.open.Disable()
+	} else {
+		f.enableIfAllowed(uris)
+	}
+}
+
+// enableIfAllowed enables the Open button unless uris break the selection
+// constraints, in which case the reason is shown beside it.
+// The reason a change was just refused takes precedence over other problems.
+func (f *fileDialog) enableIfAllowed(uris []fyne.URI) {
+	problem := f.selectionNotice
+	if err := f.selectionError(uris); err != nil {
+		f.open.Disable()
+		if problem == "" {
+			problem = err.Error()
+		}
 	} else {
 		f.open.Enable()
 	}
+	f.showSelectionProblem(problem)
+}
+
+func (f *fileDialog) showSelectionProblem(text string) {
+	if f.selectionProblem == nil {
+		return
+	}
+	f.selectionProblem.SetText(text)
+	if text == "" {
+		f.selectionProblem.Hide()
+	} else {
+		f.selectionProblem.Show()
+	}
 }
 
 func (f *fileDialog) handleConfirmTapped() {
 	if f.isFolderMode() {
-		target := f.dir
-		if len(f.selected) == 1 {
-			for _, u := range f.selected {
-				if l, err := storage.ListerForURI(u); err == nil {
-					target = l
-				}
+		var targets []fyne.ListableURI
+		for _, u := range f.selectedURIs() {
+			if l, err := storage.ListerForURI(u); err == nil {
+				targets = append(targets, l)
 			}
 		}
-		f.Hide()
-		if f.folderCallback != nil {
-			f.folderCallback(target, nil)
+		if len(targets) == 0 && f.dir != nil {
+			targets = []fyne.ListableURI{f.dir}
 		}
+		f.Hide()
+		f.folderResult(targets, nil)
 		return
 	}
 
@@ -896,6 +1381,20 @@ func (f *fileDialog) handleConfirmTapped() {
 		return
 	}
 
+	if f.isFileOrFolderMode() {
+		uris := f.selectedURIs()
+		for i, u := range uris {
+			if l, err := storage.ListerForURI(u); err == nil {
+				uris[i] = l
+			}
+		}
+		f.Hide()
+		if f.uriCallback != nil {
+			f.uriCallback(uris, nil)
+		}
+		return
+	}
+
 	if len(f.selected) == 1 {
 		var u fyne.URI
 		for _, val := range f.selected {
@@ -909,17 +1408,212 @@ func (f *fileDialog) handleConfirmTapped() {
 		}
 	}
 
-	var readers []fyne.URIReadCloser
-	for _, u := range f.selected {
-		r, err := storage.Reader(u)
-		if err == nil {
-			readers = append(readers, r)
-		}
+	if f.uriCallback != nil {
+		uris := f.selectedURIs()
+		f.Hide()
+		f.uriCallback(uris, nil)
+		return
 	}
+
+	readers, err := openReaders(f.selectedURIs())
 	f.Hide()
 	if f.callback != nil {
-		f.callback(readers, nil)
+		f.callback(readers, err)
+	}
+}
+
+// folderResult passes the chosen folders to the folder callback in use.
+// A single folder callback receives the first one.
+func (f *fileDialog) folderResult(dirs []fyne.ListableURI, err error) {
+	if f.foldersCallback != nil {
+		f.foldersCallback(dirs, err)
+		return
+	}
+	if f.folderCallback != nil {
+		var dir fyne.ListableURI
+		if len(dirs) > 0 {
+			dir = dirs[0]
+		}
+		f.folderCallback(dir, err)
+	}
+}
+
+// SetSelectionOrder sets whether selected items are returned in list order
+// (the default) or in the order they were clicked.
+func (f *fileDialog) SetSelectionOrder(order SelectionOrder) {
+	f.selectionOrder = order
+	f.orderCustomized = false
+	f.updateFooter()
+}
+
+// SetPersistentSelection lets the selection build up across folders. Selected
+// items survive navigation, are listed in the selection tray and are all
+// returned on confirm. It only applies to dialogs that allow multiple items.
+func (f *fileDialog) SetPersistentSelection(persistent bool) {
+	f.persistentSelection = persistent && f.allowMultiple
+	f.updateFooter()
+}
+
+// SetSelectedURIs selects uris, first moving to the folder holding the first
+// of them. Before the dialog is shown this sets where it opens; the items are
+// selected and scrolled into view once that folder is listed.
+func (f *fileDialog) SetSelectedURIs(uris []fyne.URI) {
+	if len(uris) == 0 {
+		f.preselect = nil
+		if f.fileList != nil {
+			f.clearSelection()
+		}
+		return
+	}
+
+	dir := f.dir
+	if parent, err := storage.Parent(uris[0]); err == nil {
+		if lister, err := listerForURI(parent); err == nil {
+			dir = lister
+		}
+	}
+	if f.fileList == nil {
+		f.dir = dir
+		f.preselect = uris
+		return
+	}
+	if dir != nil && (f.dir == nil || dir.String() != f.dir.String()) {
+		f.SetLocation(dir)
+	}
+	f.selectURIs(uris)
+}
+
+// clearSelection deselects everything, including items in other folders.
+func (f *fileDialog) clearSelection() {
+	f.selected = make(map[string]fyne.URI)
+	f.selectionNotice = ""
+	f.order = nil
+	f.orderCustomized = false
+	f.updateFooter()
+	if f.fileList != nil {
+		f.fileList.refresh()
+	}
+}
+
+// setSelection replaces the selection with uris. Items that stay selected keep
+// their place in the click order; new ones follow in the order given.
+//
+// With a persistent selection only the listed items are replaced; items
+// picked in other folders, or hidden by the search, stay selected.
+func (f *fileDialog) setSelection(uris []fyne.URI) {
+	next := make(map[string]fyne.URI, len(uris))
+	if f.persistentSelection {
+		for key, u := range f.selected {
+			next[key] = u
+		}
+		if f.fileList != nil {
+			for _, u := range f.fileList.filtered {
+				delete(next, u.String())
+			}
+		}
+	}
+	for _, u := range uris {
+		next[u.String()] = u
+	}
+	order := make([]string, 0, len(next))
+	kept := make(map[string]bool, len(next))
+	for _, key := range f.order {
+		if _, ok := next[key]; ok && !kept[key] {
+			order = append(order, key)
+			kept[key] = true
+		}
+	}
+	for _, u := range uris {
+		if key := u.String(); !kept[key] {
+			order = append(order, key)
+			kept[key] = true
+		}
+	}
+	f.selected = next
+	f.order = order
+	f.selectionNotice = ""
+	if len(next) < 2 {
+		f.orderCustomized = false
+	}
+}
+
+func (f *fileDialog) addSelected(u fyne.URI) {
+	key := u.String()
+	if _, ok := f.selected[key]; ok {
+		return
+	}
+	f.selected[key] = u
+	f.order = append(f.order, key)
+	f.selectionNotice = ""
+}
+
+func (f *fileDialog) removeSelected(key string) {
+	delete(f.selected, key)
+	f.order = slices.DeleteFunc(f.order, func(k string) bool { return k == key })
+	f.selectionNotice = ""
+	if len(f.selected) < 2 {
+		f.orderCustomized = false
+	}
+}
+
+// moveSelected moves the selected item at index of selectedURIs by delta places.
+// The rearranged order is kept from then on.
+func (f *fileDialog) moveSelected(index, delta int) {
+	uris := f.selectedURIs()
+	to := index + delta
+	if index < 0 || index >= len(uris) || to < 0 || to >= len(uris) {
+		return
 	}
+	uris[index], uris[to] = uris[to], uris[index]
+	f.order = f.order[:0]
+	for _, u := range uris {
+		f.order = append(f.order, u.String())
+	}
+	f.orderCustomized = true
+	f.updateFooter()
+}
+
+// selectedURIs returns the selection in list order, or in click order when
+// that was requested or the user rearranged it.
+func (f *fileDialog) selectedURIs() []fyne.URI {
+	uris := make([]fyne.URI, 0, len(f.selected))
+	seen := make(map[string]bool, len(f.selected))
+	if f.selectionOrder == SelectionOrderClick || f.orderCustomized {
+		for _, key := range f.order {
+			if u, ok := f.selected[key]; ok && !seen[key] {
+				uris = append(uris, u)
+				seen[key] = true
+			}
+		}
+	}
+	if f.fileList != nil {
+		for _, u := range f.fileList.filtered {
+			if sel, ok := f.selected[u.String()]; ok && !seen[u.String()] {
+				uris = append(uris, sel)
+				seen[u.String()] = true
+			}
+		}
+	}
+	// Items that are not listed, e.g. picked in other folders, follow in click order.
+	for _, key := range f.order {
+		if u, ok := f.selected[key]; ok && !seen[key] {
+			uris = append(uris, u)
+			seen[key] = true
+		}
+	}
+	if len(uris) < len(f.selected) {
+		var rest []string
+		for key := range f.selected {
+			if !seen[key] {
+				rest = append(rest, key)
+			}
+		}
+		slices.Sort(rest)
+		for _, key := range rest {
+			uris = append(uris, f.selected[key])
+		}
+	}
+	return uris
 }
 
 func (f *fileDialog) handleSaveTapped() {
@@ -989,7 +1683,7 @@ func (f *fileDialog) createSaveWriter(target fyne.URI) {
 
 func (f *fileDialog) confirmOverwriteDialog(target fyne.URI, confirm func(bool)) {
 	msg := fmt.Sprintf(lang.L("A file named %q already exists. Replace it?"), target.Name())
-	dialog.ShowConfirm(lang.L("Replace File"), msg, confirm, f.parent)
+	dialog.ShowConfirm(lang.L("Replace File"), msg, confirm, f.host())
 }
 
 func (f *fileDialog) updateSaveNameFromSelection() {
@@ -1074,24 +1768,30 @@ func (f *fileDialog) saveTargetURI(input string) (fyne.URI, error) {
 }
 
 func (f *fileDialog) loadPrefs() {
-	f.showHidden = fyne.CurrentApp().Preferences().Bool(showHiddenKey)
+	f.showHidden = fyne.CurrentApp().Preferences().Bool(f.prefKey(showHiddenKey))
 
-	view := ViewLayout(fyne.CurrentApp().Preferences().Int(viewLayoutKey))
+	view := ViewLayout(fyne.CurrentApp().Preferences().Int(f.prefKey(viewLayoutKey)))
 	if view != GridView && view != ListView {
 		view = GridView
 	}
 	f.view = view
 
-	f.zoomLevel = clampZoomLevelIndex(fyne.CurrentApp().Preferences().Int(zoomLevelKey))
+	f.zoomLevel = clampZoomLevelIndex(fyne.CurrentApp().Preferences().Int(f.prefKey(zoomLevelKey)))
+
+	f.previewVisible = fyne.CurrentApp().Preferences().Bool(f.prefKey(previewVisibleKey))
+	f.previewWidth = fyne.CurrentApp().Preferences().FloatWithFallback(f.prefKey(previewWidthKey), defaultPreviewWidth)
+	if f.previewWidth < 0.1 || f.previewWidth > 0.7 {
+		f.previewWidth = defaultPreviewWidth
+	}
 }
 
 // Helpers
 
 func isHidden(file fyne.URI) bool {
-	if file.Scheme() != "file" {
+	if file.Scheme() != "file" && file.Scheme() != archiveScheme {
 		return false
 	}
-	name := filepath.Base(file.Path())
+	name := file.Name()
 	return name == "" || name[0] == '.'
 }
 
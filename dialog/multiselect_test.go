@@ -1,8 +1,11 @@
 package dialog
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -395,3 +398,463 @@ func TestFileSaveDialog_OverwriteUsesConfirmation(t *testing.T) {
 	}
 	_ = gotWriter.Close()
 }
+
+func TestFileOrFolderDialog_ReturnsFilesAndFolders(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	w := a.NewWindow("Test")
+	root := t.TempDir()
+	dirChild := filepath.Join(root, "child")
+	fileChild := filepath.Join(root, "file.txt")
+	if err := os.MkdirAll(dirChild, 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	if err := os.WriteFile(fileChild, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	var got []fyne.URI
+	d := NewFileOrFolderOpen(func(uris []fyne.URI, err error) {
+		if err != nil {
+			t.Fatalf("unexpected callback error: %v", err)
+		}
+		got = uris
+	}, w, true).(*fileDialog)
+
+	d.makeUI()
+	lister, err := storage.ListerForURI(storage.NewFileURI(root))
+	if err != nil {
+		t.Fatalf("lister failed: %v", err)
+	}
+	d.refreshDir(lister)
+	if len(d.fileList.filtered) != 2 {
+		t.Fatalf("expected the file and the folder to be listed, got %v", d.fileList.filtered)
+	}
+
+	d.Select(0)
+	d.ToggleSelection(1)
+	if d.open.Disabled() {
+		t.Fatal("expected Open to be enabled for a mixed selection")
+	}
+
+	d.open.OnTapped()
+	if len(got) != 2 {
+		t.Fatalf("expected 2 URIs, got %v", got)
+	}
+	// Folders sort first and come back listable.
+	if _, ok := got[0].(fyne.ListableURI); !ok || got[0].Path() != dirChild {
+		t.Errorf("expected listable folder %q first, got %#v", dirChild, got[0])
+	}
+	if got[1].Path() != fileChild {
+		t.Errorf("expected file %q second, got %q", fileChild, got[1].Path())
+	}
+}
+
+func TestFoldersDialog_ReturnsAllSelectedFolders(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	w := a.NewWindow("Test")
+	root := t.TempDir()
+	for _, name := range []string{"a", "b", "c"} {
+		if err := os.MkdirAll(filepath.Join(root, name), 0o755); err != nil {
+			t.Fatalf("mkdir failed: %v", err)
+		}
+	}
+
+	var got []fyne.ListableURI
+	calls := 0
+	d := NewFoldersOpen(func(dirs []fyne.ListableURI, err error) {
+		if err != nil {
+			t.Fatalf("unexpected callback error: %v", err)
+		}
+		calls++
+		got = dirs
+	}, w).(*fileDialog)
+
+	d.makeUI()
+	lister, err := storage.ListerForURI(storage.NewFileURI(root))
+	if err != nil {
+		t.Fatalf("lister failed: %v", err)
+	}
+	d.refreshDir(lister)
+
+	d.Select(0)
+	d.ExtendSelection(2)
+	if want := "3 folders selected: "; !strings.HasPrefix(d.fileName.Text, want) {
+		t.Errorf("footer = %q, want the folder count", d.fileName.Text)
+	}
+
+	d.open.OnTapped()
+	if calls != 1 || len(got) != 3 {
+		t.Fatalf("expected 3 folders in one callback, got %d calls with %v", calls, got)
+	}
+	for i, name := range []string{"a", "b", "c"} {
+		if got[i].Path() != filepath.Join(root, name) {
+			t.Errorf("folder %d = %q, want %q", i, got[i].Path(), name)
+		}
+	}
+
+	// With nothing selected the current folder is chosen.
+	d.refreshDir(lister)
+	d.open.OnTapped()
+	if len(got) != 1 || got[0].Path() != root {
+		t.Errorf("expected the current folder, got %v", got)
+	}
+}
+
+func TestFileOpenURIs_ReturnsURIsWithoutOpening(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	w := a.NewWindow("Test")
+	root := t.TempDir()
+	for _, name := range []string{"1.txt", "2.txt", "3.txt"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+
+	var got []fyne.URI
+	d := NewFileOpenURIs(func(uris []fyne.URI, err error) {
+		if err != nil {
+			t.Fatalf("unexpected callback error: %v", err)
+		}
+		got = uris
+	}, w, true).(*fileDialog)
+
+	d.makeUI()
+	lister, err := storage.ListerForURI(storage.NewFileURI(root))
+	if err != nil {
+		t.Fatalf("lister failed: %v", err)
+	}
+	d.refreshDir(lister)
+	d.Select(0)
+	d.ExtendSelection(2)
+
+	// A file that can no longer be opened is still returned; the app decides what to do.
+	if err := os.Remove(filepath.Join(root, "2.txt")); err != nil {
+		t.Fatalf("remove failed: %v", err)
+	}
+	d.open.OnTapped()
+	if len(got) != 3 {
+		t.Fatalf("expected all 3 URIs, got %v", got)
+	}
+	for i, name := range []string{"1.txt", "2.txt", "3.txt"} {
+		if got[i].Name() != name {
+			t.Errorf("URI %d = %q, want %q", i, got[i].Name(), name)
+		}
+	}
+}
+
+func TestFileDialog_SelectionOrder(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	w := a.NewWindow("Test")
+	root := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "c.txt", "d.txt"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+
+	var got []string
+	d := NewFileOpenURIs(func(uris []fyne.URI, _ error) {
+		got = nil
+		for _, u := range uris {
+			got = append(got, u.Name())
+		}
+	}, w, true).(*fileDialog)
+	d.makeUI()
+	lister, _ := storage.ListerForURI(storage.NewFileURI(root))
+	names := func() []string {
+		var out []string
+		for _, u := range d.selectedURIs() {
+			out = append(out, u.Name())
+		}
+		return out
+	}
+
+	// List order is the default, whatever the clicks.
+	d.refreshDir(lister)
+	d.Select(2)
+	d.ToggleSelection(0)
+	d.ToggleSelection(3)
+	if want := []string{"a.txt", "c.txt", "d.txt"}; !slices.Equal(names(), want) {
+		t.Errorf("list order = %v, want %v", names(), want)
+	}
+	if d.fileName.Text != "a.txt, c.txt, d.txt" {
+		t.Errorf("footer = %q", d.fileName.Text)
+	}
+	if !d.tray.content.Visible() || len(d.tray.items.Objects) != 3 {
+		t.Errorf("expected the tray to show 3 items")
+	}
+
+	d.SetSelectionOrder(SelectionOrderClick)
+	if want := []string{"c.txt", "a.txt", "d.txt"}; !slices.Equal(names(), want) {
+		t.Errorf("click order = %v, want %v", names(), want)
+	}
+
+	// Shift ranges are added walking away from the anchor (d.txt, the last click).
+	d.ExtendSelection(1)
+	if want := []string{"c.txt", "d.txt", "b.txt"}; !slices.Equal(names(), want) {
+		t.Errorf("range click order = %v, want %v", names(), want)
+	}
+
+	// Rearranging in the tray wins over list order and is what gets returned.
+	d.SetSelectionOrder(SelectionOrderList)
+	d.moveSelected(0, 1)
+	if want := []string{"c.txt", "b.txt", "d.txt"}; !slices.Equal(names(), want) {
+		t.Errorf("rearranged order = %v, want %v", names(), want)
+	}
+	d.open.OnTapped()
+	if want := []string{"c.txt", "b.txt", "d.txt"}; !slices.Equal(got, want) {
+		t.Errorf("returned %v, want %v", got, want)
+	}
+}
+
+func TestFileOpen_ReportsFilesThatFailToOpen(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	w := a.NewWindow("Test")
+	root := t.TempDir()
+	for _, name := range []string{"1.txt", "2.txt", "3.txt"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+
+	var readers []fyne.URIReadCloser
+	var cbErr error
+	d := NewFileOpen(func(r []fyne.URIReadCloser, err error) {
+		readers, cbErr = r, err
+	}, w, true).(*fileDialog)
+	d.makeUI()
+	lister, _ := storage.ListerForURI(storage.NewFileURI(root))
+	d.refreshDir(lister)
+	d.Select(0)
+	d.ExtendSelection(2)
+	if err := os.Remove(filepath.Join(root, "2.txt")); err != nil {
+		t.Fatalf("remove failed: %v", err)
+	}
+
+	d.open.OnTapped()
+	for _, r := range readers {
+		r.Close()
+	}
+	if len(readers) != 2 {
+		t.Errorf("expected the 2 readers that opened, got %d", len(readers))
+	}
+	var selErr *SelectionError
+	if !errors.As(cbErr, &selErr) {
+		t.Fatalf("expected a *SelectionError, got %v", cbErr)
+	}
+	if len(selErr.Failures) != 1 || selErr.Failures[0].URI.Name() != "2.txt" {
+		t.Fatalf("unexpected failures %+v", selErr.Failures)
+	}
+	if !errors.Is(cbErr, os.ErrNotExist) {
+		t.Errorf("expected the cause to be reachable with errors.Is, got %v", cbErr)
+	}
+}
+
+func TestFileDialog_PersistentSelectionAcrossFolders(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	w := a.NewWindow("Test")
+	root := t.TempDir()
+	first, second := filepath.Join(root, "first"), filepath.Join(root, "second")
+	for _, dir := range []string{first, second} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir failed: %v", err)
+		}
+		for _, name := range []string{"a.txt", "b.txt"} {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644); err != nil {
+				t.Fatalf("write failed: %v", err)
+			}
+		}
+	}
+
+	var got []string
+	d := NewFileOpenURIs(func(uris []fyne.URI, _ error) {
+		for _, u := range uris {
+			got = append(got, u.Path())
+		}
+	}, w, true).(*fileDialog)
+	d.SetPersistentSelection(true)
+	d.makeUI()
+
+	firstDir, _ := storage.ListerForURI(storage.NewFileURI(first))
+	secondDir, _ := storage.ListerForURI(storage.NewFileURI(second))
+
+	d.SetLocation(firstDir)
+	d.Select(1)
+	if !d.tray.content.Visible() {
+		t.Error("expected the tray to show a single persistent selection")
+	}
+
+	d.SetLocation(secondDir)
+	if len(d.selected) != 1 {
+		t.Fatalf("expected the selection to survive navigation, got %v", d.selected)
+	}
+	// A plain click replaces the selection in this folder only.
+	d.Select(0)
+	d.Select(1)
+	if len(d.selected) != 2 {
+		t.Fatalf("expected one item from each folder, got %v", d.selected)
+	}
+
+	// Items re-listed after navigating back are still shown as selected.
+	d.SetLocation(firstDir)
+	if !d.IsSelected(d.fileList.filtered[1]) || d.IsSelected(d.fileList.filtered[0]) {
+		t.Error("expected first/b.txt to be selected after navigating back")
+	}
+
+	d.open.OnTapped()
+	want := []string{filepath.Join(first, "b.txt"), filepath.Join(second, "b.txt")}
+	if !slices.Equal(got, want) {
+		t.Errorf("returned %v, want %v", got, want)
+	}
+}
+
+func TestFileDialog_SelectionConstraints(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	w := a.NewWindow("Test")
+	root := t.TempDir()
+	for name, size := range map[string]int{"a.bin": 100, "b.bin": 100, "c.bin": 100, "d.bin": 500} {
+		if err := os.WriteFile(filepath.Join(root, name), make([]byte, size), 0o644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+
+	d := NewFileOpenURIs(func([]fyne.URI, error) {}, w, true).(*fileDialog)
+	d.SetSelectionConstraints(SelectionConstraints{
+		MinItems:      2,
+		MaxItems:      2,
+		MaxTotalBytes: 400,
+		Validate: func(uris []fyne.URI) error {
+			for _, u := range uris {
+				if u.Name() == "c.bin" {
+					return errors.New("c.bin is not allowed")
+				}
+			}
+			return nil
+		},
+	})
+	d.makeUI()
+	lister, _ := storage.ListerForURI(storage.NewFileURI(root))
+	d.refreshDir(lister)
+
+	d.Select(0)
+	if !d.open.Disabled() || d.selectionProblem.Text != "Select at least 2 items" {
+		t.Errorf("expected the minimum to block Open, got %q", d.selectionProblem.Text)
+	}
+
+	d.ToggleSelection(1)
+	if d.open.Disabled() || d.selectionProblem.Visible() {
+		t.Errorf("expected two small files to be accepted, got %q", d.selectionProblem.Text)
+	}
+
+	// Going over the hard limits is refused and the selection is kept.
+	d.ToggleSelection(2)
+	if len(d.selected) != 2 || d.IsSelected(d.fileList.filtered[2]) {
+		t.Fatalf("expected a third item to be refused, got %v", d.selected)
+	}
+	if d.open.Disabled() || !strings.Contains(d.selectionProblem.Text, "at most 2") {
+		t.Errorf("expected the refusal to be explained, got %q", d.selectionProblem.Text)
+	}
+	d.ExtendSelection(3)
+	if len(d.selected) != 2 || d.IsSelected(d.fileList.filtered[3]) {
+		t.Fatalf("expected the range to be refused, got %v", d.selected)
+	}
+
+	d.ToggleSelection(0)
+	d.ToggleSelection(3)
+	if d.IsSelected(d.fileList.filtered[3]) || !strings.Contains(d.selectionProblem.Text, "limit is 400 B") {
+		t.Errorf("expected the size limit to refuse d.bin, got %q", d.selectionProblem.Text)
+	}
+
+	d.ToggleSelection(2)
+	if !d.open.Disabled() || d.selectionProblem.Text != "c.bin is not allowed" {
+		t.Errorf("expected the validator to block Open, got %q", d.selectionProblem.Text)
+	}
+
+	// Bulk selections are cut down to what fits, with the reason shown.
+	d.SelectMultiple([]int{0, 1, 2, 3})
+	if got := d.selectedURIs(); len(got) != 2 || got[0].Name() != "a.bin" || got[1].Name() != "b.bin" {
+		t.Errorf("select all kept %v, want the first two items", got)
+	}
+	if !strings.Contains(d.selectionProblem.Text, "at most 2") {
+		t.Errorf("expected the cut to be explained, got %q", d.selectionProblem.Text)
+	}
+	d.clearSelection()
+	d.SetSelectedURIs([]fyne.URI{d.fileList.filtered[0], d.fileList.filtered[3]})
+	if got := d.selectedURIs(); len(got) != 1 || got[0].Name() != "a.bin" || !strings.Contains(d.selectionProblem.Text, "limit is 400 B") {
+		t.Errorf("SetSelectedURIs kept %v (%q), want only a.bin within the size limit", got, d.selectionProblem.Text)
+	}
+}
+
+func TestFileDialog_SelectionHelpers(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	w := a.NewWindow("Test")
+	root := t.TempDir()
+	for _, name := range []string{"frame_0001.exr", "frame_0002.exr", "frame_0003.EXR", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(root, "renders"), 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+
+	d := NewFileOpenURIs(func([]fyne.URI, error) {}, w, true).(*fileDialog)
+	d.makeUI()
+	lister, _ := storage.ListerForURI(storage.NewFileURI(root))
+	d.refreshDir(lister)
+
+	names := func() []string {
+		var got []string
+		for _, u := range d.selectedURIs() {
+			got = append(got, u.Name())
+		}
+		return got
+	}
+
+	// Globs ignore case and folders are never picked in file mode.
+	if err := d.selectMatching("*.exr", false); err != nil {
+		t.Fatalf("selectMatching failed: %v", err)
+	}
+	if got := names(); !slices.Equal(got, []string{"frame_0001.exr", "frame_0002.exr", "frame_0003.EXR"}) {
+		t.Errorf("glob selected %v", got)
+	}
+
+	if err := d.selectMatching(`_000[23]\.`, true); err != nil {
+		t.Fatalf("selectMatching failed: %v", err)
+	}
+	if got := names(); !slices.Equal(got, []string{"frame_0002.exr", "frame_0003.EXR"}) {
+		t.Errorf("regex selected %v", got)
+	}
+	if err := d.selectMatching("[", true); err == nil {
+		t.Error("expected an invalid regular expression to be reported")
+	}
+
+	d.InvertSelection()
+	if got := names(); !slices.Equal(got, []string{"frame_0001.exr", "notes.txt"}) {
+		t.Errorf("inverted selection = %v", got)
+	}
+	if d.fileName.Text != "frame_0001.exr, notes.txt" {
+		t.Errorf("expected the footer to follow, got %q", d.fileName.Text)
+	}
+
+	d.SelectSameType(storage.NewFileURI(filepath.Join(root, "frame_0001.exr")))
+	if got := names(); len(got) != 3 {
+		t.Errorf("same type selected %v", got)
+	}
+}
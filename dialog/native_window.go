@@ -0,0 +1,83 @@
+package dialog
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/widget"
+)
+
+// defaultWindowSize is the size of the dialog's own window until the user
+// resizes it.
+var defaultWindowSize = fyne.NewSize(1000, 700)
+
+// host is the window the dialog is shown in: its own window when
+// Options.NativeWindow is set, otherwise the parent.
+func (f *fileDialog) host() fyne.Window {
+	if f.window != nil {
+		return f.window
+	}
+	return f.parent
+}
+
+// isShown reports whether the dialog is open as a popup or in its own window.
+func (f *fileDialog) isShown() bool {
+	return (f.win != nil && f.win.Visible()) || f.window != nil
+}
+
+// showWindow opens the dialog in a window of its own. Fyne has no modal
+// windows, so the parent is covered by a popup until the dialog closes.
+// fyne.Window cannot report or set its position, so only the size is
+// restored and the window is centred each time.
+func (f *fileDialog) showWindow() {
+	prefs := fyne.CurrentApp().Preferences()
+	size := fyne.NewSize(
+		float32(prefs.FloatWithFallback(f.prefKey(windowWidthKey), float64(defaultWindowSize.Width))),
+		float32(prefs.FloatWithFallback(f.prefKey(windowHeightKey), float64(defaultWindowSize.Height))),
+	)
+
+	f.window = fyne.CurrentApp().NewWindow(f.titleText())
+	// Closing the window cancels, as the Cancel button does.
+	f.window.SetCloseIntercept(func() {
+		if f.dismiss != nil {
+			f.dismiss.OnTapped()
+		}
+	})
+	f.window.SetContent(f.makeUI())
+	f.window.Resize(size)
+	f.window.CenterOnScreen()
+
+	if f.parent != nil {
+		note := widget.NewLabel(lang.L("The file picker is open in another window."))
+		show := widget.NewButton(lang.L("Show"), func() {
+			if f.window != nil {
+				f.window.RequestFocus()
+			}
+		})
+		f.blocker = widget.NewModalPopUp(container.NewVBox(note, container.NewCenter(show)), f.parent.Canvas())
+		f.blocker.Show()
+	}
+	f.window.Show()
+}
+
+// closeWindow saves the size of the dialog's own window and closes it.
+func (f *fileDialog) closeWindow() {
+	if f.blocker != nil {
+		f.blocker.Hide()
+		f.blocker = nil
+	}
+	if f.window == nil {
+		return
+	}
+	w := f.window
+	f.window = nil
+	// The Quick Look popup belongs to the closed window's canvas.
+	f.quickLook = nil
+
+	if size := w.Canvas().Size(); size.Width > 0 && size.Height > 0 {
+		prefs := fyne.CurrentApp().Preferences()
+		prefs.SetFloat(f.prefKey(windowWidthKey), float64(size.Width))
+		prefs.SetFloat(f.prefKey(windowHeightKey), float64(size.Height))
+	}
+	w.Close()
+}
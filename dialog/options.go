@@ -0,0 +1,249 @@
+package dialog
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/storage"
+)
+
+// Options configures a dialog created by one of the New*WithOptions
+// functions. Zero values keep the default behaviour.
+type Options struct {
+	// Location is the folder the dialog starts in. Defaults to the home folder.
+	Location fyne.ListableURI
+	// Title replaces the title derived from the dialog kind.
+	Title string
+	// ConfirmLabel and DismissLabel replace the text of the Open or Save
+	// and Cancel buttons.
+	ConfirmLabel string
+	DismissLabel string
+	// Filter limits the files listed; folders are always shown.
+	Filter storage.FileFilter
+	// FileName is the suggested name in save dialogs.
+	FileName string
+	// AllowMultiple lets more than one item be chosen. It is ignored by save
+	// and single folder dialogs.
+	AllowMultiple bool
+
+	// View is the initial layout. The default uses the saved layout.
+	View ViewLayout
+	// Zoom is the initial item scale, such as 1.5. Zero uses the saved zoom.
+	// It is rounded to the nearest supported level.
+	Zoom float32
+	// Sort is the initial sort order.
+	Sort FileSortOrder
+	// ShowHidden lists hidden files regardless of the saved preference.
+	ShowHidden bool
+
+	// Selected are items to select when the dialog first lists its folder.
+	// Items that are not listed there are ignored, unless the selection is
+	// persistent.
+	Selected []fyne.URI
+	// RevealURI opens the dialog in the folder holding this item, selected
+	// and scrolled into view. It takes precedence over Location.
+	RevealURI fyne.URI
+
+	// NativeWindow shows the dialog in a window of its own instead of a popup
+	// over the parent, which is blocked until the dialog closes. The window's
+	// size is remembered, but not its position: Fyne cannot read or set window
+	// positions, so it opens centred. It has no effect on mobile or in a
+	// FileBrowser.
+	NativeWindow bool
+
+	// ThumbnailManager generates thumbnails and previews for this dialog.
+	// Defaults to the manager returned by GetThumbnailManager.
+	ThumbnailManager *ThumbnailManager
+	// PreferencesNamespace keeps this dialog's saved view, zoom, preview and
+	// hidden file settings apart from other dialogs in the app.
+	PreferencesNamespace string
+
+	// OnLocationChanged is called when the dialog lists a different folder,
+	// including the one it opens in.
+	OnLocationChanged func(dir fyne.ListableURI)
+	// OnSelectionChanged is called with the selection, in the order it will
+	// be returned, whenever its items or their order change.
+	OnSelectionChanged func(uris []fyne.URI)
+	// OnFilterChanged is called when SetFilter replaces the file filter.
+	OnFilterChanged func(filter storage.FileFilter)
+	// OnViewChanged is called when the view switches between list and grid.
+	OnViewChanged func(view ViewLayout)
+}
+
+// NewFileOpenWithOptions creates a file dialog allowing the user to choose one
+// or more files to open. As with NewFileOpen, the readers must be closed even
+// when err is a *SelectionError.
+func NewFileOpenWithOptions(callback func(readers []fyne.URIReadCloser, err error), parent fyne.Window, opts Options) dialog.Dialog {
+	d := newDialogWithOptions(parent, openDialogModeFile, opts)
+	d.callback = callback
+	return d
+}
+
+// NewFileOpenURIsWithOptions creates a file dialog returning the URIs of the
+// chosen files rather than open readers.
+func NewFileOpenURIsWithOptions(callback func(uris []fyne.URI, err error), parent fyne.Window, opts Options) dialog.Dialog {
+	d := newDialogWithOptions(parent, openDialogModeFile, opts)
+	d.uriCallback = callback
+	return d
+}
+
+// NewFileSaveWithOptions creates a file dialog allowing the user to choose a file path for saving.
+func NewFileSaveWithOptions(callback func(writer fyne.URIWriteCloser, err error), parent fyne.Window, opts Options) dialog.Dialog {
+	opts.AllowMultiple = false
+	d := newDialogWithOptions(parent, openDialogModeSave, opts)
+	d.saveCallback = callback
+	return d
+}
+
+// NewFolderOpenWithOptions creates a folder dialog allowing the user to choose a single folder.
+func NewFolderOpenWithOptions(callback func(dir fyne.ListableURI, err error), parent fyne.Window, opts Options) dialog.Dialog {
+	opts.AllowMultiple = false
+	d := newDialogWithOptions(parent, openDialogModeFolder, opts)
+	d.folderCallback = callback
+	return d
+}
+
+// NewFoldersOpenWithOptions creates a folder dialog allowing the user to choose
+// one or more folders, whatever opts.AllowMultiple is set to.
+func NewFoldersOpenWithOptions(callback func(dirs []fyne.ListableURI, err error), parent fyne.Window, opts Options) dialog.Dialog {
+	opts.AllowMultiple = true
+	d := newDialogWithOptions(parent, openDialogModeFolder, opts)
+	d.foldersCallback = callback
+	return d
+}
+
+// NewFileOrFolderOpenWithOptions creates a dialog allowing the user to choose files and folders together.
+func NewFileOrFolderOpenWithOptions(callback func(uris []fyne.URI, err error), parent fyne.Window, opts Options) dialog.Dialog {
+	d := newDialogWithOptions(parent, openDialogModeFileOrFolder, opts)
+	d.uriCallback = callback
+	return d
+}
+
+func newDialogWithOptions(parent fyne.Window, mode openDialogMode, opts Options) *fileDialog {
+	d := newDialogBase(parent)
+	d.mode = mode
+	d.allowMultiple = opts.AllowMultiple
+	d.prefNamespace = opts.PreferencesNamespace
+	d.loadPrefs()
+
+	if opts.Location != nil {
+		d.dir = opts.Location
+	}
+	d.title = opts.Title
+	d.confirmLabel = opts.ConfirmLabel
+	d.dismissLabel = opts.DismissLabel
+	d.extensionFilter = opts.Filter
+	d.defaultSaveName = opts.FileName
+	if opts.View == ListView || opts.View == GridView {
+		d.view = opts.View
+	}
+	if opts.Zoom > 0 {
+		d.zoomLevel = nearestZoomLevelIndex(opts.Zoom)
+	}
+	d.sortOrder = opts.Sort
+	if opts.ShowHidden {
+		d.showHidden = true
+	}
+	d.preselect = opts.Selected
+	d.thumbs = opts.ThumbnailManager
+	d.nativeWindow = opts.NativeWindow
+	d.hooks = dialogHooks{
+		onLocationChanged:  opts.OnLocationChanged,
+		onSelectionChanged: opts.OnSelectionChanged,
+		onFilterChanged:    opts.OnFilterChanged,
+		onViewChanged:      opts.OnViewChanged,
+	}
+	if opts.RevealURI != nil {
+		d.SetSelectedURIs(append([]fyne.URI{opts.RevealURI}, opts.Selected...))
+	}
+	return d
+}
+
+// thumbnails returns the manager used for this dialog's thumbnails.
+func (f *fileDialog) thumbnails() *ThumbnailManager {
+	if f.thumbs != nil {
+		return f.thumbs
+	}
+	return GetThumbnailManager()
+}
+
+// prefKey returns key within the dialog's preferences namespace.
+func (f *fileDialog) prefKey(key string) string {
+	if f.prefNamespace == "" {
+		return key
+	}
+	return f.prefNamespace + ":" + key
+}
+
+// applyPreselection selects the items given in Options.Selected or
+// SetSelectedURIs once the starting folder is listed.
+func (f *fileDialog) applyPreselection() {
+	if len(f.preselect) == 0 || f.isSaveMode() {
+		return
+	}
+	uris := f.preselect
+	f.preselect = nil
+	f.selectURIs(uris)
+}
+
+// selectURIs selects uris in the current listing, in the order given, and
+// scrolls the first of them to the centre. Unlisted items are ignored, unless
+// the selection is persistent. Single selection dialogs select only the first
+// listed item.
+func (f *fileDialog) selectURIs(uris []fyne.URI) {
+	ids := make(map[string]int)
+	if f.fileList != nil {
+		for id, u := range f.fileList.filtered {
+			ids[u.String()] = id
+		}
+	}
+	var listed []fyne.URI
+	first := -1
+	for _, u := range uris {
+		id, ok := ids[u.String()]
+		if !ok && !f.persistentSelection {
+			continue
+		}
+		listed = append(listed, u)
+		if ok && first == -1 {
+			first = id
+		}
+	}
+	if len(listed) == 0 {
+		return
+	}
+	if !f.allowMultiple {
+		listed = listed[:1]
+		if id, ok := ids[listed[0].String()]; ok {
+			first = id
+		} else {
+			first = -1
+		}
+	}
+	f.setSelectionWithinLimits(listed)
+	if first != -1 {
+		f.anchor = first
+	}
+	f.updateSaveNameFromSelection()
+	f.updateFooter()
+	if f.fileList != nil {
+		f.fileList.refresh()
+		if first != -1 {
+			f.fileList.scrollCenterOnID(f.view, first, f.zoomScale())
+		}
+	}
+}
+
+// sortLabel is the sort menu entry showing order.
+func sortLabel(order FileSortOrder) string {
+	switch order {
+	case SortNameDesc:
+		return lang.L("Name (Z-A)")
+	case SortSizeAsc, SortSizeDesc:
+		return lang.L("Size")
+	case SortDateAsc, SortDateDesc:
+		return lang.L("Date")
+	default:
+		return lang.L("Name (A-Z)")
+	}
+}
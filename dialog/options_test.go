@@ -0,0 +1,266 @@
+package dialog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/test"
+)
+
+func TestFileOpenWithOptions(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	root := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "c.png", ".notes.txt"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	dir, _ := storage.ListerForURI(storage.NewFileURI(root))
+	thumbs := NewThumbnailManager(ThumbnailOptions{})
+
+	w := a.NewWindow("Test")
+	w.Resize(fyne.NewSize(1200, 800))
+	d := NewFileOpenWithOptions(func([]fyne.URIReadCloser, error) {}, w, Options{
+		Location:      dir,
+		Title:         "Pick Renders",
+		ConfirmLabel:  "Import",
+		DismissLabel:  "Back",
+		Filter:        storage.NewExtensionFileFilter([]string{".txt"}),
+		AllowMultiple: true,
+		View:          ListView,
+		Zoom:          1.6,
+		Sort:          SortNameDesc,
+		ShowHidden:    true,
+		Selected: []fyne.URI{
+			storage.NewFileURI(filepath.Join(root, "b.txt")),
+			storage.NewFileURI(filepath.Join(root, "missing.txt")),
+		},
+		ThumbnailManager:     thumbs,
+		PreferencesNamespace: "importer",
+	}).(*fileDialog)
+	d.Show()
+	defer d.Hide()
+
+	if d.dir.String() != dir.String() || d.view != ListView || zoomLevels[d.zoomLevel] != 1.5 {
+		t.Errorf("dir/view/zoom = %s/%v/%v", d.dir, d.view, zoomLevels[d.zoomLevel])
+	}
+	if d.open.Text != "Import" || d.dismiss.Text != "Back" {
+		t.Errorf("button labels = %q/%q", d.open.Text, d.dismiss.Text)
+	}
+	var names []string
+	for _, u := range d.fileList.filtered {
+		names = append(names, u.Name())
+	}
+	if len(names) != 3 || names[0] != "b.txt" || names[1] != "a.txt" || names[2] != ".notes.txt" {
+		t.Errorf("listing = %v, want the .txt files, hidden included, in descending order", names)
+	}
+	if got := d.selectedURIs(); len(got) != 1 || got[0].Name() != "b.txt" {
+		t.Errorf("preselected %v, want only the listed b.txt", got)
+	}
+	if d.fileList.thumbnails() != thumbs || d.preview.thumbs != thumbs {
+		t.Error("expected the dialog's own thumbnail manager to be used")
+	}
+
+	d.SetView(GridView)
+	prefs := a.Preferences()
+	if prefs.Int("importer:"+viewLayoutKey) != int(GridView) || prefs.Int(viewLayoutKey) == int(GridView) {
+		t.Error("expected the view to be saved in the dialog's namespace only")
+	}
+}
+
+func TestFileDialog_RevealAndSetSelectedURIs(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	root := t.TempDir()
+	media := filepath.Join(root, "media")
+	if err := os.Mkdir(media, 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	for i := range 200 {
+		name := filepath.Join(media, fmt.Sprintf("clip_%03d.mov", i))
+		if err := os.WriteFile(name, []byte("x"), 0o644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+
+	w := a.NewWindow("Test")
+	w.Resize(fyne.NewSize(1200, 800))
+	target := storage.NewFileURI(filepath.Join(media, "clip_150.mov"))
+	// A single selection dialog picks the revealed item over others listed first.
+	d := NewFileOpenWithOptions(func([]fyne.URIReadCloser, error) {}, w, Options{
+		RevealURI: target,
+		Selected:  []fyne.URI{storage.NewFileURI(filepath.Join(media, "clip_010.mov"))},
+		View:      ListView,
+	}).(*fileDialog)
+	d.Show()
+	defer d.Hide()
+
+	if filepath.Clean(d.dir.Path()) != media {
+		t.Fatalf("opened in %s, want the revealed item's folder", d.dir.Path())
+	}
+	if got := d.selectedURIs(); len(got) != 1 || got[0].String() != target.String() {
+		t.Fatalf("selected %v, want %s", got, target)
+	}
+	if d.fileList.list.GetScrollOffset() <= 0 {
+		t.Error("expected the list to scroll the revealed item into view")
+	}
+
+	// Selecting items elsewhere moves to their folder.
+	other := storage.NewFileURI(filepath.Join(root, "notes.txt"))
+	if err := os.WriteFile(other.Path(), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	d.SetSelectedURIs([]fyne.URI{other})
+	if filepath.Clean(d.dir.Path()) != root || !d.IsSelected(other) || len(d.selected) != 1 {
+		t.Errorf("dir = %s, selected = %v", d.dir.Path(), d.selected)
+	}
+
+	d.SetSelectedURIs(nil)
+	if len(d.selected) != 0 {
+		t.Errorf("expected an empty list to clear the selection, got %v", d.selected)
+	}
+}
+
+func TestFileDialog_Hooks(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	root := t.TempDir()
+	sub := filepath.Join(root, "sub")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	dir, _ := storage.ListerForURI(storage.NewFileURI(root))
+	subDir, _ := storage.ListerForURI(storage.NewFileURI(sub))
+
+	var locations []string
+	var selections [][]string
+	var filters []storage.FileFilter
+	var views []ViewLayout
+	w := a.NewWindow("Test")
+	d := NewFileOpenWithOptions(func([]fyne.URIReadCloser, error) {}, w, Options{
+		Location:      dir,
+		AllowMultiple: true,
+		View:          ListView,
+		OnLocationChanged: func(dir fyne.ListableURI) {
+			locations = append(locations, filepath.Base(filepath.Clean(dir.Path())))
+		},
+		OnSelectionChanged: func(uris []fyne.URI) {
+			var names []string
+			for _, u := range uris {
+				names = append(names, u.Name())
+			}
+			selections = append(selections, names)
+		},
+		OnFilterChanged: func(filter storage.FileFilter) { filters = append(filters, filter) },
+		OnViewChanged:   func(view ViewLayout) { views = append(views, view) },
+	}).(*fileDialog)
+	d.Show()
+	defer d.Hide()
+
+	// Sorted folders first: sub, a.txt, b.txt.
+	d.Select(1)
+	d.ToggleSelection(2)
+	d.ToggleSelection(2)
+	d.Refresh()
+	d.SetFilter(storage.NewExtensionFileFilter([]string{".txt"}))
+	d.SetView(GridView)
+	d.SetLocation(subDir)
+
+	if want := []string{filepath.Base(root), "sub"}; !slices.Equal(locations, want) {
+		t.Errorf("locations = %v, want %v", locations, want)
+	}
+	want := [][]string{{"a.txt"}, {"a.txt", "b.txt"}, {"a.txt"}, nil}
+	if !slices.EqualFunc(selections, want, slices.Equal) {
+		t.Errorf("selections = %v, want %v", selections, want)
+	}
+	if len(filters) != 1 || filters[0] == nil {
+		t.Errorf("filters = %v", filters)
+	}
+	if !slices.Equal(views, []ViewLayout{GridView}) {
+		t.Errorf("views = %v", views)
+	}
+
+	// Showing the dialog again reports its folder again.
+	d.Hide()
+	locations = nil
+	d.Show()
+	if want := []string{"sub"}; !slices.Equal(locations, want) {
+		t.Errorf("locations after reshowing = %v, want %v", locations, want)
+	}
+}
+
+func TestFileDialog_NativeWindow(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	dir, _ := storage.ListerForURI(storage.NewFileURI(root))
+
+	parent := a.NewWindow("Tool")
+	parent.Resize(fyne.NewSize(300, 200))
+	var got []fyne.URI
+	d := NewFileOpenURIsWithOptions(func(uris []fyne.URI, _ error) { got = uris }, parent, Options{
+		Location:     dir,
+		NativeWindow: true,
+	}).(*fileDialog)
+	d.Show()
+
+	if d.window == nil || d.win != nil {
+		t.Fatal("expected the dialog in its own window rather than a popup")
+	}
+	if d.window.Canvas().Size().Width < 1000 {
+		t.Errorf("window width = %v, want it independent of the parent", d.window.Canvas().Size().Width)
+	}
+	if d.blocker == nil || !d.blocker.Visible() {
+		t.Error("expected the parent to be blocked while the dialog is open")
+	}
+
+	// Showing again while open keeps the same window and blocker.
+	window, blocker := d.window, d.blocker
+	d.Show()
+	if d.window != window || d.blocker != blocker {
+		t.Error("expected a second Show to leave the open window alone")
+	}
+
+	d.window.Resize(fyne.NewSize(1200, 900))
+	d.Select(0)
+	d.open.OnTapped()
+	if len(got) != 1 || got[0].Name() != "a.txt" {
+		t.Errorf("callback got %v", got)
+	}
+	if d.window != nil || d.blocker != nil {
+		t.Error("expected the window and blocker to be gone after confirming")
+	}
+
+	// The next dialog opens at the remembered size.
+	got = []fyne.URI{nil}
+	d = NewFileOpenURIsWithOptions(func(uris []fyne.URI, _ error) { got = uris }, parent, Options{
+		Location:     dir,
+		NativeWindow: true,
+	}).(*fileDialog)
+	d.Show()
+	if size := d.window.Canvas().Size(); size.Width != 1200 || size.Height != 900 {
+		t.Errorf("reopened at %v, want the saved 1200x900", size)
+	}
+	d.dismiss.OnTapped()
+	if got != nil || d.window != nil {
+		t.Errorf("expected cancelling to close the window with no result, got %v", got)
+	}
+}
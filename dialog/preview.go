@@ -0,0 +1,230 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	// previewImageSize is the edge length previews are rendered at.
+	previewImageSize = 512
+	// previewMinDialogWidth is the width below which the preview pane is hidden automatically.
+	previewMinDialogWidth = 760
+	// defaultPreviewWidth is the default share of the file area used by the preview pane.
+	defaultPreviewWidth = 0.3
+	previewDelay        = 150 * time.Millisecond
+)
+
+// previewPane shows a large preview and the details of the focused item.
+type previewPane struct {
+	content *container.Scroll
+
+	image      *canvas.Image
+	icon       *widget.FileIcon
+	text       *widget.RichText
+	textScroll *container.Scroll
+	name       *widget.Label
+	details    *fyne.Container
+
+	thumbs    *ThumbnailManager
+	uri       fyne.URI
+	loadTimer *time.Timer
+	// cancel drops the pending preview of the previous item.
+	cancel context.CancelFunc
+}
+
+func newPreviewPane(thumbs *ThumbnailManager) *previewPane {
+	p := &previewPane{
+		thumbs:  thumbs,
+		image:   canvas.NewImageFromImage(nil),
+		icon:    widget.NewFileIcon(nil),
+		text:    widget.NewRichText(),
+		name:    widget.NewLabelWithStyle("", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		details: container.New(layout.NewFormLayout()),
+	}
+	p.image.FillMode = canvas.ImageFillContain
+	p.image.SetMinSize(fyne.NewSize(200, 200))
+	p.image.Hide()
+	p.textScroll = container.NewScroll(p.text)
+	p.textScroll.SetMinSize(fyne.NewSize(200, 300))
+	p.textScroll.Hide()
+	p.name.Wrapping = fyne.TextWrapBreak
+
+	visual := container.NewStack(p.icon, p.image, p.textScroll)
+	p.content = container.NewVScroll(container.NewPadded(container.NewVBox(visual, p.name, widget.NewSeparator(), p.details)))
+	p.update(nil)
+	return p
+}
+
+// update shows u in the pane, or an empty pane when u is nil.
+func (p *previewPane) update(u fyne.URI) {
+	if p.uri != nil && u != nil && p.uri.String() == u.String() {
+		return
+	}
+	p.uri = u
+	if p.loadTimer != nil {
+		p.loadTimer.Stop()
+	}
+	if p.cancel != nil {
+		p.cancel()
+		p.cancel = nil
+	}
+
+	p.image.Image = nil
+	p.image.Hide()
+	p.textScroll.Hide()
+	p.text.Segments = nil
+	p.icon.SetURI(u)
+	p.icon.Show()
+	if u == nil {
+		p.name.SetText(lang.L("No Selection"))
+		p.setDetails(nil)
+		return
+	}
+	p.name.SetText(u.Name())
+	p.setDetails(previewDetails(u, mediaInfo{}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.loadTimer = time.AfterFunc(previewDelay, func() {
+		if p.thumbs.detectFileType(u).text {
+			p.loadText(u)
+			return
+		}
+		p.thumbs.LoadPreviewContext(ctx, u, previewImageSize, func(img *canvas.Image) {
+			fyne.Do(func() {
+				if p.uri == nil || p.uri.String() != u.String() {
+					return
+				}
+				p.image.Image = img.Image
+				p.image.Show()
+				p.image.Refresh()
+				p.icon.Hide()
+				info, _ := p.thumbs.loadMediaInfo(thumbnailID(u))
+				p.setDetails(previewDetails(u, info))
+			})
+		})
+		p.thumbs.LoadMetadata(u, func(info mediaInfo) {
+			fyne.Do(func() {
+				if p.uri == nil || p.uri.String() != u.String() {
+					return
+				}
+				p.setDetails(previewDetails(u, info))
+			})
+		})
+	})
+}
+
+// loadText shows the start of a text file with syntax highlighting.
+// It is called off the UI thread.
+func (p *previewPane) loadText(u fyne.URI) {
+	text, truncated, err := readTextPreview(u)
+	if err != nil {
+		return
+	}
+	segments := highlightText(text, syntaxForFile(u.Name(), text))
+	if truncated {
+		segments = append(segments, codeSegment("\n…", theme.ColorNamePlaceHolder))
+	}
+
+	fyne.Do(func() {
+		if p.uri == nil || p.uri.String() != u.String() {
+			return
+		}
+		p.text.Segments = segments
+		p.text.Refresh()
+		p.textScroll.ScrollToTop()
+		p.textScroll.Show()
+		p.icon.Hide()
+	})
+}
+
+type previewDetail struct {
+	label, value string
+}
+
+func (p *previewPane) setDetails(rows []previewDetail) {
+	p.details.Objects = nil
+	for _, row := range rows {
+		value := widget.NewLabel(row.value)
+		value.Wrapping = fyne.TextWrapBreak
+		p.details.Add(widget.NewLabelWithStyle(row.label, fyne.TextAlignTrailing, fyne.TextStyle{Bold: true}))
+		p.details.Add(value)
+	}
+	p.details.Refresh()
+}
+
+// previewDetails lists what we know about u. Local files are inspected with
+// os.Stat; media metadata comes from the preview render.
+func previewDetails(u fyne.URI, info mediaInfo) []previewDetail {
+	var rows []previewDetail
+	add := func(label, value string) {
+		if value != "" {
+			rows = append(rows, previewDetail{label, value})
+		}
+	}
+
+	location := u.String()
+	if u.Scheme() == "file" {
+		location = u.Path()
+		if stat, err := os.Stat(u.Path()); err == nil {
+			if stat.IsDir() {
+				add(lang.L("Kind"), lang.L("Folder"))
+			} else {
+				add(lang.L("Size"), formatFileSize(stat.Size()))
+			}
+			add(lang.L("Modified"), formatPreviewTime(stat.ModTime()))
+			if created, ok := fileCreatedTime(stat); ok {
+				add(lang.L("Created"), formatPreviewTime(created))
+			}
+			add(lang.L("Permissions"), stat.Mode().Perm().String())
+		}
+	} else if isDir, _ := storage.CanList(u); isDir {
+		add(lang.L("Kind"), lang.L("Folder"))
+	}
+
+	add(lang.L("Dimensions"), info.resolution())
+	add(lang.L("Duration"), formatMediaDuration(info.Duration))
+	add(lang.L("Format"), info.Format)
+	add(lang.L("Camera"), info.Camera)
+	add(lang.L("Lens"), info.Lens)
+	add(lang.L("Exposure"), info.exposureSummary())
+	add(lang.L("Taken"), formatPreviewTime(info.Taken))
+	if info.GPS {
+		add(lang.L("GPS"), lang.L("Location recorded"))
+	}
+	add(lang.L("Location"), location)
+	return rows
+}
+
+func formatPreviewTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+// formatFileSize renders n bytes using binary units, e.g. "1.5 MB".
+func formatFileSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
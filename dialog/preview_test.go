@@ -0,0 +1,157 @@
+package dialog
+
+import (
+	"context"
+	"image/color"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/test"
+)
+
+func TestFormatFileSize(t *testing.T) {
+	cases := map[int64]string{
+		0:                      "0 B",
+		1023:                   "1023 B",
+		1024:                   "1.0 KB",
+		1536:                   "1.5 KB",
+		5 * 1024 * 1024:        "5.0 MB",
+		3 * 1024 * 1024 * 1024: "3.0 GB",
+	}
+	for n, want := range cases {
+		if got := formatFileSize(n); got != want {
+			t.Errorf("formatFileSize(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestPreviewDetails_LocalFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, make([]byte, 2048), 0o640); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	got := map[string]string{}
+	for _, row := range previewDetails(storage.NewFileURI(path), mediaInfo{Width: 640, Height: 480}) {
+		got[row.label] = row.value
+	}
+	if got["Size"] != "2.0 KB" {
+		t.Errorf("unexpected size %q", got["Size"])
+	}
+	if got["Permissions"] != "-rw-r-----" {
+		t.Errorf("unexpected permissions %q", got["Permissions"])
+	}
+	if got["Dimensions"] != "640×480" {
+		t.Errorf("unexpected dimensions %q", got["Dimensions"])
+	}
+	if got["Location"] != path {
+		t.Errorf("unexpected location %q", got["Location"])
+	}
+	if got["Modified"] == "" {
+		t.Error("expected modification date")
+	}
+	if _, ok := got["Duration"]; ok {
+		t.Error("expected empty values to be omitted")
+	}
+}
+
+func TestFileDialog_PreviewFollowsSelectionAndWidth(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	root := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	a.Preferences().SetBool(previewVisibleKey, true)
+
+	w := a.NewWindow("Test")
+	d := NewFileOpen(func([]fyne.URIReadCloser, error) {}, w, true).(*fileDialog)
+	content := container.NewStack(d.makeUI())
+	w.SetContent(content)
+	w.Resize(fyne.NewSize(1000, 700))
+
+	lister, err := storage.ListerForURI(storage.NewFileURI(root))
+	if err != nil {
+		t.Fatalf("lister failed: %v", err)
+	}
+	d.refreshDir(lister)
+	d.updatePreviewVisibility()
+	if !d.preview.content.Visible() {
+		t.Fatal("expected preview pane to be visible")
+	}
+
+	d.Select(1)
+	if d.preview.uri == nil || d.preview.uri.Name() != d.fileList.filtered[1].Name() {
+		t.Fatalf("expected preview of selected item, got %v", d.preview.uri)
+	}
+	d.ToggleSelection(1)
+	if d.preview.uri != nil {
+		t.Fatalf("expected empty preview without selection, got %v", d.preview.uri)
+	}
+
+	// Narrow dialogs hide the pane without changing the preference.
+	w.Resize(fyne.NewSize(500, 700))
+	d.updatePreviewVisibility()
+	if d.preview.content.Visible() {
+		t.Fatal("expected preview pane to be hidden when narrow")
+	}
+	if !d.previewVisible {
+		t.Fatal("expected preference to stay enabled")
+	}
+
+	w.Resize(fyne.NewSize(1000, 700))
+	d.updatePreviewVisibility()
+	d.previewSplit.SetOffset(0.6)
+	d.setPreviewVisible(false)
+	if d.preview.content.Visible() || a.Preferences().Bool(previewVisibleKey) {
+		t.Fatal("expected preview pane to be hidden and preference cleared")
+	}
+	if w := a.Preferences().Float(previewWidthKey); w < 0.39 || w > 0.41 {
+		t.Fatalf("expected dragged width to be saved, got %v", w)
+	}
+}
+
+func TestThumbnailManager_LoadPreviewContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.png")
+	if err := os.WriteFile(path, testPNG(t, color.White), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	u := storage.NewFileURI(path)
+	tm := &ThumbnailManager{}
+
+	// Hold every slot so the previews have to wait their turn.
+	slots := tm.previewSlots()
+	for range maxConcurrentPreviews {
+		slots <- struct{}{}
+	}
+	var stale atomic.Bool
+	ctx, cancel := context.WithCancel(context.Background())
+	tm.LoadPreviewContext(ctx, u, 64, func(*canvas.Image) { stale.Store(true) })
+	done := make(chan struct{})
+	tm.LoadPreview(u, 64, func(*canvas.Image) { close(done) })
+
+	cancel()
+	for range maxConcurrentPreviews {
+		<-slots
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the preview")
+	}
+	// Give the cancelled preview time to run if it was going to.
+	time.Sleep(50 * time.Millisecond)
+	if stale.Load() {
+		t.Error("expected the cancelled preview to be dropped")
+	}
+}
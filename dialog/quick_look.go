@@ -0,0 +1,237 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"image"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// quickLookScale is the share of the window covered by the Quick Look overlay.
+const quickLookScale = 0.92
+
+// quickLook is a near full window overlay showing the focused item at full
+// size. Arrow keys step through the listing and keep the selection in sync.
+type quickLook struct {
+	dialog *fileDialog
+	popup  *widget.PopUp
+	// canvas is the canvas popup was built on. A dialog in its own window
+	// gets a new canvas each time it is shown.
+	canvas fyne.Canvas
+
+	name        *widget.Label
+	position    *widget.Label
+	image       *canvas.Image
+	icon        *widget.FileIcon
+	text        *widget.RichText
+	textScroll  *container.Scroll
+	storyboard  *fyne.Container
+	boardScroll *container.Scroll
+
+	uri fyne.URI
+	// cancel drops the pending preview of the previous item.
+	cancel context.CancelFunc
+}
+
+func newQuickLook(f *fileDialog) *quickLook {
+	q := &quickLook{
+		dialog:     f,
+		name:       widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		position:   widget.NewLabel(""),
+		image:      canvas.NewImageFromImage(nil),
+		icon:       widget.NewFileIcon(nil),
+		text:       widget.NewRichText(),
+		storyboard: container.NewGridWithColumns(5),
+	}
+	q.name.Truncation = fyne.TextTruncateEllipsis
+	q.image.FillMode = canvas.ImageFillContain
+	q.image.ScaleMode = canvas.ImageScaleSmooth
+	q.textScroll = container.NewScroll(q.text)
+	q.boardScroll = container.NewVScroll(q.storyboard)
+
+	prev := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() { q.step(-1) })
+	next := widget.NewButtonWithIcon("", theme.NavigateNextIcon(), func() { q.step(1) })
+	closeBtn := widget.NewButtonWithIcon("", theme.CancelIcon(), q.hide)
+	header := container.NewBorder(nil, nil, nil, container.NewHBox(q.position, prev, next, closeBtn), q.name)
+
+	// The icon is shown centred at a large size while nothing better is available.
+	iconBox := container.NewCenter(container.NewGridWrap(fyne.NewSquareSize(fileIconSize*2), q.icon))
+	body := container.NewStack(iconBox, q.image, q.textScroll, q.boardScroll)
+	content := container.NewBorder(container.NewVBox(header, widget.NewSeparator()), nil, nil, nil, body)
+
+	q.canvas = f.host().Canvas()
+	q.popup = widget.NewModalPopUp(content, q.canvas)
+	return q
+}
+
+func (q *quickLook) visible() bool {
+	return q != nil && q.popup.Visible()
+}
+
+// show opens the overlay on u, sized to the current window.
+func (q *quickLook) show(u fyne.URI) {
+	c := q.dialog.host().Canvas()
+	c.Unfocus()
+	q.popup.Resize(fyne.NewSize(c.Size().Width*quickLookScale, c.Size().Height*quickLookScale))
+	q.popup.Show()
+	q.load(u)
+}
+
+func (q *quickLook) hide() {
+	q.uri = nil
+	q.cancelPreview()
+	q.popup.Hide()
+}
+
+func (q *quickLook) cancelPreview() {
+	if q.cancel != nil {
+		q.cancel()
+		q.cancel = nil
+	}
+}
+
+// step moves the selection by delta items and shows the new item.
+func (q *quickLook) step(delta int) {
+	f := q.dialog
+	if f.fileList == nil || len(f.fileList.filtered) == 0 {
+		return
+	}
+	id := clampIndex(f.anchor+delta, len(f.fileList.filtered))
+	if id == f.anchor {
+		return
+	}
+	f.Select(id)
+	f.fileList.scrollTo(id)
+	q.load(f.fileList.filtered[id])
+}
+
+// typedKey handles navigation keys while the overlay is open. Space closes it
+// from typedRuneHook, as the rune follows the key event.
+func (q *quickLook) typedKey(ev *fyne.KeyEvent) {
+	switch ev.Name {
+	case fyne.KeyLeft, fyne.KeyUp:
+		q.step(-1)
+	case fyne.KeyRight, fyne.KeyDown:
+		q.step(1)
+	case fyne.KeyEscape:
+		q.hide()
+	}
+}
+
+func (q *quickLook) load(u fyne.URI) {
+	q.uri = u
+	q.cancelPreview()
+	q.name.SetText(u.Name())
+	if f := q.dialog; f.fileList != nil && f.anchor >= 0 {
+		q.position.SetText(fmt.Sprintf("%d / %d", f.anchor+1, len(f.fileList.filtered)))
+	}
+
+	q.image.Image = nil
+	q.image.Hide()
+	q.textScroll.Hide()
+	q.storyboard.Objects = nil
+	q.boardScroll.Hide()
+	q.icon.SetURI(u)
+	q.icon.Show()
+
+	if isDir, _ := storage.CanList(u); isDir {
+		return
+	}
+
+	thumbs := q.dialog.thumbnails()
+	ctx, cancel := context.WithCancel(context.Background())
+	q.cancel = cancel
+	go func() {
+		ft := thumbs.detectFileType(u)
+		switch {
+		case ft.isImage():
+			if img, _, err := decodeImageURI(u); err == nil {
+				q.showImage(u, img)
+				return
+			}
+		case ft.isVideo() && u.Scheme() == "file":
+			thumbs.loadStoryboard(u, func(sb *storyboard) {
+				if sb != nil {
+					q.showStoryboard(u, sb)
+					return
+				}
+				q.loadPreview(ctx, u)
+			})
+			return
+		case ft.text:
+			if text, truncated, err := readTextPreview(u); err == nil {
+				segments := highlightText(text, syntaxForFile(u.Name(), text))
+				if truncated {
+					segments = append(segments, codeSegment("\n…", theme.ColorNamePlaceHolder))
+				}
+				q.showText(u, segments)
+				return
+			}
+		}
+		q.loadPreview(ctx, u)
+	}()
+}
+
+// loadPreview shows the rendered preview of u, for items that cannot be shown
+// at full size.
+func (q *quickLook) loadPreview(ctx context.Context, u fyne.URI) {
+	q.dialog.thumbnails().LoadPreviewContext(ctx, u, previewImageSize*2, func(img *canvas.Image) {
+		q.showImage(u, img.Image)
+	})
+}
+
+// current reports whether u is still the item on display; results of
+// superseded loads are dropped.
+func (q *quickLook) current(u fyne.URI) bool {
+	return q.uri != nil && q.uri.String() == u.String()
+}
+
+func (q *quickLook) showImage(u fyne.URI, img image.Image) {
+	fyne.Do(func() {
+		if !q.current(u) {
+			return
+		}
+		q.image.Image = img
+		q.image.Show()
+		q.image.Refresh()
+		q.icon.Hide()
+	})
+}
+
+func (q *quickLook) showStoryboard(u fyne.URI, sb *storyboard) {
+	fyne.Do(func() {
+		if !q.current(u) {
+			return
+		}
+		q.storyboard.Objects = nil
+		for _, frame := range sb.frames {
+			img := canvas.NewImageFromImage(frame)
+			img.FillMode = canvas.ImageFillContain
+			img.SetMinSize(fyne.NewSquareSize(storyboardFrameSize))
+			q.storyboard.Add(img)
+		}
+		q.storyboard.Refresh()
+		q.boardScroll.ScrollToTop()
+		q.boardScroll.Show()
+		q.icon.Hide()
+	})
+}
+
+func (q *quickLook) showText(u fyne.URI, segments []widget.RichTextSegment) {
+	fyne.Do(func() {
+		if !q.current(u) {
+			return
+		}
+		q.text.Segments = segments
+		q.text.Refresh()
+		q.textScroll.ScrollToTop()
+		q.textScroll.Show()
+		q.icon.Hide()
+	})
+}
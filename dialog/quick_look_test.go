@@ -0,0 +1,118 @@
+package dialog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+)
+
+func TestQuickLook_SpaceOpensAndArrowsFollowSelection(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	root := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(name), 0o644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+
+	w := a.NewWindow("Test")
+	w.Resize(fyne.NewSize(1000, 700))
+	d := NewFileOpen(func([]fyne.URIReadCloser, error) {}, w, true).(*fileDialog)
+	d.win = widget.NewModalPopUp(d.makeUI(), w.Canvas())
+	d.win.Show()
+	lister, err := storage.ListerForURI(storage.NewFileURI(root))
+	if err != nil {
+		t.Fatalf("lister failed: %v", err)
+	}
+	d.refreshDir(lister)
+
+	// Without a selection Space falls through to type-to-search.
+	w.Canvas().Unfocus()
+	d.typedRuneHook(' ')
+	if d.quickLook.visible() {
+		t.Fatal("expected Quick Look to stay closed without a selection")
+	}
+	d.searchEntry.SetText("")
+
+	d.Select(0)
+	w.Canvas().Unfocus()
+	d.typedRuneHook(' ')
+	if !d.quickLook.visible() {
+		t.Fatal("expected Space to open Quick Look")
+	}
+	if d.searchEntry.Text != "" {
+		t.Fatalf("expected search to be untouched, got %q", d.searchEntry.Text)
+	}
+	if d.quickLook.name.Text != "a.txt" {
+		t.Fatalf("expected a.txt in Quick Look, got %q", d.quickLook.name.Text)
+	}
+
+	d.typedKeyHook(&fyne.KeyEvent{Name: fyne.KeyRight})
+	d.typedKeyHook(&fyne.KeyEvent{Name: fyne.KeyDown})
+	if d.quickLook.name.Text != "c.txt" || d.quickLook.position.Text != "3 / 3" {
+		t.Fatalf("expected c.txt at 3 / 3, got %q at %q", d.quickLook.name.Text, d.quickLook.position.Text)
+	}
+	if len(d.selected) != 1 || !d.IsSelected(d.fileList.filtered[2]) {
+		t.Fatalf("expected selection to follow Quick Look, got %v", d.selected)
+	}
+
+	// Stepping past the end keeps the last item.
+	d.typedKeyHook(&fyne.KeyEvent{Name: fyne.KeyRight})
+	if d.anchor != 2 {
+		t.Fatalf("expected anchor to stay at the last item, got %d", d.anchor)
+	}
+
+	d.typedKeyHook(&fyne.KeyEvent{Name: fyne.KeyLeft})
+	if !d.IsSelected(d.fileList.filtered[1]) {
+		t.Fatal("expected Left to select the previous item")
+	}
+
+	d.typedKeyHook(&fyne.KeyEvent{Name: fyne.KeyEscape})
+	if d.quickLook.visible() {
+		t.Fatal("expected Escape to close Quick Look")
+	}
+	if !d.win.Visible() {
+		t.Fatal("expected the dialog to stay open")
+	}
+
+	d.typedRuneHook(' ')
+	d.typedRuneHook(' ')
+	if d.quickLook.visible() {
+		t.Fatal("expected a second Space to close Quick Look")
+	}
+}
+
+func TestQuickLook_ReshownNativeWindow(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("a"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	dir, _ := storage.ListerForURI(storage.NewFileURI(root))
+
+	parent := a.NewWindow("Tool")
+	d := NewFileOpenURIsWithOptions(func([]fyne.URI, error) {}, parent, Options{
+		Location:     dir,
+		NativeWindow: true,
+	}).(*fileDialog)
+
+	for i := range 2 {
+		d.Show()
+		d.Select(0)
+		d.window.Canvas().Unfocus()
+		d.typedRuneHook(' ')
+		if !d.quickLook.visible() || d.quickLook.canvas != d.window.Canvas() {
+			t.Fatalf("show %d: expected Quick Look on the dialog's current window", i+1)
+		}
+		d.dismiss.OnTapped()
+	}
+}
@@ -0,0 +1,133 @@
+package dialog
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/lang"
+)
+
+// SelectionConstraints limit what can be confirmed in an open dialog. Zero
+// values mean no limit.
+//
+// MaxItems and MaxTotalBytes are hard limits: selections that go over them
+// are refused. MinItems and Validate only keep the dialog from being
+// confirmed, with the reason shown next to the Open button.
+type SelectionConstraints struct {
+	MinItems int
+	MaxItems int
+	// MaxTotalBytes caps the combined size of the selected local files.
+	MaxTotalBytes int64
+	// Validate is called with the selection, in the order it will be
+	// returned, whenever it changes. A non-nil error blocks confirmation and
+	// its message is shown to the user.
+	Validate func([]fyne.URI) error
+}
+
+// SetSelectionConstraints sets the limits the selection has to meet before
+// the dialog can be confirmed. It has no effect on save dialogs, nor on
+// Android and iOS, where the platform's own picker is shown instead.
+func (f *fileDialog) SetSelectionConstraints(c SelectionConstraints) {
+	f.constraints = c
+	f.selectionNotice = ""
+	f.updateFooter()
+}
+
+func (c SelectionConstraints) isZero() bool {
+	return c.MinItems <= 0 && c.MaxItems <= 0 && c.MaxTotalBytes <= 0 && c.Validate == nil
+}
+
+// trySelection applies change and keeps it only if the resulting selection
+// stays within the hard limits. Otherwise the previous selection is restored
+// and the reason is shown in the footer. It reports whether change was kept.
+func (f *fileDialog) trySelection(change func()) bool {
+	selected, order, customized := maps.Clone(f.selected), slices.Clone(f.order), f.orderCustomized
+	change()
+	if err := f.selectionLimitError(f.selectedURIs()); err != nil {
+		f.selected, f.order, f.orderCustomized = selected, order, customized
+		f.selectionNotice = err.Error()
+		return false
+	}
+	f.selectionNotice = ""
+	return true
+}
+
+// setSelectionWithinLimits is setSelection for bulk changes such as Select
+// All or a marquee. If uris go over a hard limit, only as many as fit, in
+// order, are selected and the reason is shown in the footer.
+func (f *fileDialog) setSelectionWithinLimits(uris []fyne.URI) {
+	if f.trySelection(func() { f.setSelection(uris) }) {
+		return
+	}
+	notice := f.selectionNotice
+	// Both limits only grow with each item, so search for the longest prefix that fits.
+	n := sort.Search(len(uris), func(i int) bool {
+		return !f.trySelection(func() { f.setSelection(uris[:i+1]) })
+	})
+	f.setSelection(uris[:n])
+	f.selectionNotice = notice
+}
+
+// selectionLimitError reports whether uris go over MaxItems or MaxTotalBytes.
+func (f *fileDialog) selectionLimitError(uris []fyne.URI) error {
+	c := f.constraints
+	if c.MaxItems > 0 && len(uris) > c.MaxItems {
+		return fmt.Errorf(lang.L("Select at most %d items"), c.MaxItems)
+	}
+	if c.MaxTotalBytes > 0 {
+		if total := f.selectionSize(uris); total > c.MaxTotalBytes {
+			return fmt.Errorf(lang.L("Selection is %s, the limit is %s"), formatFileSize(total), formatFileSize(c.MaxTotalBytes))
+		}
+	}
+	return nil
+}
+
+// selectionError reports why uris cannot be confirmed, checking the hard
+// limits first, then MinItems and finally the Validate hook.
+func (f *fileDialog) selectionError(uris []fyne.URI) error {
+	if err := f.selectionLimitError(uris); err != nil {
+		return err
+	}
+	c := f.constraints
+	if c.MinItems > 0 && len(uris) < c.MinItems {
+		return fmt.Errorf(lang.L("Select at least %d items"), c.MinItems)
+	}
+	if c.Validate != nil {
+		if err := c.Validate(uris); err != nil {
+			if err.Error() == "" {
+				return errors.New(lang.L("Selection is not allowed"))
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// selectionSize adds up the sizes of the local files in uris. Folders and
+// other locations count as empty. Sizes are cached until the folder is
+// listed again, as the selection is checked on every change.
+func (f *fileDialog) selectionSize(uris []fyne.URI) int64 {
+	if f.fileSizes == nil {
+		f.fileSizes = make(map[string]int64)
+	}
+	var total int64
+	for _, u := range uris {
+		if u.Scheme() != "file" {
+			continue
+		}
+		size, ok := f.fileSizes[u.String()]
+		if !ok {
+			if stat, err := os.Stat(u.Path()); err == nil && !stat.IsDir() {
+				size = stat.Size()
+			}
+			f.fileSizes[u.String()] = size
+		}
+		total += size
+	}
+	return total
+}
@@ -0,0 +1,61 @@
+package dialog
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+)
+
+// SelectionFailure is a selected URI that could not be opened.
+type SelectionFailure struct {
+	URI fyne.URI
+	Err error
+}
+
+// SelectionError is passed to the open callback, together with the readers
+// that did open, when some of the selected files could not be opened. Those
+// readers are still owned by the callback and must be closed.
+type SelectionError struct {
+	Failures []SelectionFailure
+}
+
+func (e *SelectionError) Error() string {
+	if len(e.Failures) == 1 {
+		return fmt.Sprintf("could not open %s: %v", e.Failures[0].URI.Name(), e.Failures[0].Err)
+	}
+	parts := make([]string, len(e.Failures))
+	for i, failure := range e.Failures {
+		parts[i] = fmt.Sprintf("%s: %v", failure.URI.Name(), failure.Err)
+	}
+	return fmt.Sprintf("could not open %d selected files: %s", len(e.Failures), strings.Join(parts, "; "))
+}
+
+// Unwrap returns the cause of each failure, for use with errors.Is and errors.As.
+func (e *SelectionError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, failure := range e.Failures {
+		errs[i] = failure.Err
+	}
+	return errs
+}
+
+// openReaders opens a reader for each URI. Failures are collected in a
+// *SelectionError instead of stopping at the first one.
+func openReaders(uris []fyne.URI) ([]fyne.URIReadCloser, error) {
+	readers := make([]fyne.URIReadCloser, 0, len(uris))
+	var failures []SelectionFailure
+	for _, u := range uris {
+		r, err := storage.Reader(u)
+		if err != nil {
+			failures = append(failures, SelectionFailure{URI: u, Err: err})
+			continue
+		}
+		readers = append(readers, r)
+	}
+	if len(failures) > 0 {
+		return readers, &SelectionError{Failures: failures}
+	}
+	return readers, nil
+}
@@ -0,0 +1,142 @@
+package dialog
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// selectionHelpers is a FilePicker offering the bulk selection actions. The
+// actions are kept off FilePicker so other implementations of it still build.
+type selectionHelpers interface {
+	FilePicker
+	InvertSelection()
+	SelectMatching()
+	SelectSameType(uri fyne.URI)
+}
+
+// InvertSelection selects the listed items that are not selected and
+// deselects the ones that are.
+func (f *fileDialog) InvertSelection() {
+	f.selectListed(func(u fyne.URI) bool { return !f.IsSelected(u) })
+}
+
+// SelectMatching asks for a glob or regular expression and selects the listed
+// items whose name matches it.
+func (f *fileDialog) SelectMatching() {
+	pattern := widget.NewEntry()
+	pattern.SetPlaceHolder("*.exr")
+	regex := widget.NewCheck(lang.L("Regular expression"), nil)
+	d := dialog.NewForm(lang.L("Select Matching"), lang.L("Select"), lang.L("Cancel"), []*widget.FormItem{
+		{Text: lang.L("Pattern"), Widget: pattern},
+		{Widget: regex},
+	}, func(ok bool) {
+		if !ok || pattern.Text == "" {
+			return
+		}
+		if err := f.selectMatching(pattern.Text, regex.Checked); err != nil {
+			dialog.ShowError(err, f.host())
+		}
+	}, f.host())
+	d.Show()
+	f.host().Canvas().Focus(pattern)
+}
+
+// selectMatching selects the listed items whose name matches pattern, a
+// case-insensitive glob, or a regular expression if regex is set.
+func (f *fileDialog) selectMatching(pattern string, regex bool) error {
+	var match func(name string) bool
+	if regex {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return err
+		}
+		match = re.MatchString
+	} else {
+		pattern = strings.ToLower(pattern)
+		if _, err := path.Match(pattern, ""); err != nil {
+			return err
+		}
+		match = func(name string) bool {
+			ok, _ := path.Match(pattern, strings.ToLower(name))
+			return ok
+		}
+	}
+	f.selectListed(func(u fyne.URI) bool { return match(u.Name()) })
+	return nil
+}
+
+// SelectSameType selects the listed items of the same type as uri: folders,
+// or files with the same extension.
+func (f *fileDialog) SelectSameType(uri fyne.URI) {
+	if uri == nil {
+		return
+	}
+	thumbs := f.thumbnails()
+	want := thumbs.sameTypeKey(uri)
+	f.selectListed(func(u fyne.URI) bool { return thumbs.sameTypeKey(u) == want })
+}
+
+// selectListed replaces the selection of the listed items with those that
+// can be chosen in this mode and satisfy keep.
+func (f *fileDialog) selectListed(keep func(fyne.URI) bool) {
+	if f.fileList == nil {
+		return
+	}
+	var ids []int
+	for id, u := range f.fileList.filtered {
+		if f.canChoose(u) && keep(u) {
+			ids = append(ids, id)
+		}
+	}
+	f.SelectMultiple(ids)
+}
+
+// canChoose reports whether u can be returned by this dialog. Folders only
+// open in file mode, so they are left out of bulk selections.
+func (f *fileDialog) canChoose(u fyne.URI) bool {
+	if f.isFileOrFolderMode() {
+		return true
+	}
+	isDir, _ := storage.CanList(u)
+	return isDir == f.isFolderMode()
+}
+
+// sameTypeKey groups files by lower case extension, falling back to the
+// detected MIME type for files without one.
+func (m *ThumbnailManager) sameTypeKey(u fyne.URI) string {
+	if isDir, _ := storage.CanList(u); isDir {
+		return "/"
+	}
+	if ext := strings.ToLower(u.Extension()); ext != "" {
+		return ext
+	}
+	return m.itemFileType(u).mime
+}
+
+// selectionMenuItems returns the bulk selection actions of p. uri is the item
+// Select All of This Type matches; the action is disabled without one.
+func selectionMenuItems(p selectionHelpers, uri fyne.URI) []*fyne.MenuItem {
+	sameType := fyne.NewMenuItem(lang.L("Select All of This Type"), func() {
+		p.SelectSameType(uri)
+		p.DismissMenu()
+	})
+	sameType.Disabled = uri == nil
+	return []*fyne.MenuItem{
+		fyne.NewMenuItem(lang.L("Invert Selection"), func() {
+			p.InvertSelection()
+			p.DismissMenu()
+		}),
+		fyne.NewMenuItem(lang.L("Select Matching…"), func() {
+			p.DismissMenu()
+			p.SelectMatching()
+		}),
+		sameType,
+	}
+}
@@ -0,0 +1,109 @@
+package dialog
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// selectionTrayMaxItems caps how many selected items the tray shows.
+const selectionTrayMaxItems = 50
+
+// selectionTray lists the selected items above the footer in the order they
+// will be returned. Items can be moved earlier or later, or removed, and the
+// list can be collapsed to a summary.
+type selectionTray struct {
+	dialog *fileDialog
+
+	content  *fyne.Container
+	toggle   *widget.Button
+	clear    *widget.Button
+	items    *fyne.Container
+	scroll   *container.Scroll
+	expanded bool
+}
+
+func newSelectionTray(f *fileDialog) *selectionTray {
+	t := &selectionTray{dialog: f, items: container.NewHBox(), expanded: true}
+	t.scroll = container.NewHScroll(t.items)
+	t.toggle = widget.NewButtonWithIcon("", theme.MenuDropDownIcon(), func() {
+		t.expanded = !t.expanded
+		f.updateFooter()
+	})
+	t.toggle.Importance = widget.LowImportance
+	t.clear = widget.NewButton(lang.L("Clear"), f.clearSelection)
+	t.clear.Importance = widget.LowImportance
+	t.content = container.NewBorder(nil, nil, t.toggle, t.clear, t.scroll)
+	t.content.Hide()
+	return t
+}
+
+// update rebuilds the tray for uris. The tray is shown for multiple items, or
+// for any selection when it persists across folders.
+func (t *selectionTray) update(uris []fyne.URI) {
+	t.items.Objects = nil
+	minItems := 2
+	if t.dialog.persistentSelection {
+		minItems = 1
+	}
+	if len(uris) < minItems {
+		t.content.Hide()
+		return
+	}
+
+	t.toggle.SetText(fmt.Sprintf(lang.L("%d selected"), len(uris)))
+	if t.expanded {
+		t.toggle.SetIcon(theme.MenuDropDownIcon())
+		for i, u := range uris {
+			if i == selectionTrayMaxItems {
+				more := widget.NewLabel(fmt.Sprintf(lang.L("+%d more"), len(uris)-i))
+				more.Importance = widget.LowImportance
+				t.items.Add(more)
+				break
+			}
+			t.items.Add(t.makeItem(i, len(uris), u))
+		}
+		t.scroll.Show()
+	} else {
+		t.toggle.SetIcon(theme.MenuExpandIcon())
+		t.scroll.Hide()
+	}
+	t.items.Refresh()
+	t.content.Show()
+}
+
+func (t *selectionTray) makeItem(index, count int, u fyne.URI) fyne.CanvasObject {
+	f := t.dialog
+	name := u.Name()
+	// Items from other folders are prefixed with their folder name.
+	if parent, err := storage.Parent(u); err == nil && f.dir != nil && parent.String() != f.dir.String() {
+		name = parent.Name() + "/" + name
+	}
+	label := widget.NewLabel(fmt.Sprintf("%d. %s", index+1, name))
+
+	earlier := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() { f.moveSelected(index, -1) })
+	later := widget.NewButtonWithIcon("", theme.NavigateNextIcon(), func() { f.moveSelected(index, 1) })
+	remove := widget.NewButtonWithIcon("", theme.CancelIcon(), func() {
+		f.removeSelected(u.String())
+		f.updateFooter()
+		if f.fileList != nil {
+			f.fileList.refresh()
+		}
+	})
+	for _, b := range []*widget.Button{earlier, later, remove} {
+		b.Importance = widget.LowImportance
+	}
+	if index == 0 {
+		earlier.Disable()
+	}
+	if index == count-1 {
+		later.Disable()
+	}
+
+	return container.NewHBox(label, earlier, later, remove, widget.NewSeparator())
+}
@@ -54,14 +54,8 @@ func newSidebar(p FilePicker) *sidebar {
 			// Unfocus to allow Type-to-Search to capture keys immediately
 			// (Sidebar list might capture keys if focused, preventing global hook fallback)
 			if fd, ok := s.picker.(*fileDialog); ok {
-				var c fyne.Canvas
-				if fd.win != nil {
-					c = fd.win.Canvas
-				} else if fd.parent != nil {
-					c = fd.parent.Canvas()
-				}
-				if c != nil {
-					c.Unfocus()
+				if fd.win != nil || fd.host() != nil {
+					fd.canvas().Unfocus()
 				}
 			}
 		}
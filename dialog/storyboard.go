@@ -0,0 +1,220 @@
+package dialog
+
+import (
+	"container/list"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+	"golang.org/x/image/draw"
+)
+
+const (
+	defaultStoryboardFrames = 10
+	storyboardFrameSize     = 128
+	storyboardSuffix        = "_sb"
+	// maxStoryboards is how many decoded storyboards are kept in memory,
+	// about 650KB each at the default frame count.
+	maxStoryboards = 32
+)
+
+// storyboard is a strip of evenly spaced video frames used for hover scrubbing.
+type storyboard struct {
+	frames []image.Image
+}
+
+func (s *storyboard) frameAt(ratio float32) image.Image {
+	if s == nil || len(s.frames) == 0 {
+		return nil
+	}
+	idx := int(ratio * float32(len(s.frames)))
+	return s.frames[clampIndex(idx, len(s.frames))]
+}
+
+// newStoryboardFromSheet slices a horizontal sprite sheet of square cells into frames.
+func newStoryboardFromSheet(sheet image.Image) *storyboard {
+	b := sheet.Bounds()
+	cell := b.Dy()
+	if cell <= 0 || b.Dx() < cell {
+		return nil
+	}
+
+	// Sub-images share the decoded sheet, so convert once to a type that supports it.
+	rgba, ok := sheet.(*image.RGBA)
+	if !ok {
+		rgba = image.NewRGBA(b)
+		draw.Draw(rgba, b, sheet, b.Min, draw.Src)
+	}
+
+	count := b.Dx() / cell
+	s := &storyboard{frames: make([]image.Image, count)}
+	for i := range count {
+		x := b.Min.X + i*cell
+		s.frames[i] = rgba.SubImage(image.Rect(x, b.Min.Y, x+cell, b.Min.Y+cell))
+	}
+	return s
+}
+
+// loadStoryboard returns the hover storyboard for a video, generating and caching
+// the sprite sheet on first use. The callback is always called, with nil if the
+// storyboard cannot be produced; once generation starts, it runs on a
+// background goroutine.
+func (m *ThumbnailManager) loadStoryboard(uri fyne.URI, callback func(*storyboard)) {
+	if uri == nil || uri.Scheme() != "file" || !m.detectFileType(uri).isVideo() {
+		callback(nil)
+		return
+	}
+	path := uri.Path()
+
+	if cached := m.storyboards.get(path); cached != nil {
+		callback(cached)
+		return
+	}
+
+	// Hovering back and forth must not spawn duplicate ffmpeg runs, so later
+	// callers wait for the storyboard already being generated.
+	m.storyboardLock.Lock()
+	if m.storyboardWaiters == nil {
+		m.storyboardWaiters = make(map[string][]func(*storyboard))
+	}
+	waiters, busy := m.storyboardWaiters[path]
+	m.storyboardWaiters[path] = append(waiters, callback)
+	m.storyboardLock.Unlock()
+	if busy {
+		return
+	}
+
+	go func() {
+		sb := m.storyboardFromDisk(path)
+		if sb == nil {
+			sb = m.generateStoryboard(path)
+		}
+		if sb != nil {
+			m.storyboards.add(path, sb)
+		}
+
+		m.storyboardLock.Lock()
+		waiters := m.storyboardWaiters[path]
+		delete(m.storyboardWaiters, path)
+		m.storyboardLock.Unlock()
+		for _, waiter := range waiters {
+			waiter(sb)
+		}
+	}()
+}
+
+// storyboardCache keeps the most recently used storyboards in memory. The
+// sprite sheets stay on disk, so evicted ones are cheap to reload.
+type storyboardCache struct {
+	lock  sync.Mutex
+	items map[string]*list.Element
+	order list.List // front is the most recently used
+}
+
+type storyboardEntry struct {
+	path string
+	sb   *storyboard
+}
+
+func (c *storyboardCache) get(path string) *storyboard {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	e, ok := c.items[path]
+	if !ok {
+		return nil
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*storyboardEntry).sb
+}
+
+func (c *storyboardCache) add(path string, sb *storyboard) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.items == nil {
+		c.items = make(map[string]*list.Element)
+	}
+	if e, ok := c.items[path]; ok {
+		e.Value.(*storyboardEntry).sb = sb
+		c.order.MoveToFront(e)
+		return
+	}
+	c.items[path] = c.order.PushFront(&storyboardEntry{path: path, sb: sb})
+	for c.order.Len() > maxStoryboards {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*storyboardEntry).path)
+	}
+}
+
+func (m *ThumbnailManager) storyboardCachePath(path string) string {
+	if m.cacheDir == "" {
+		return ""
+	}
+	key, err := m.generateCacheKey(path)
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(m.cacheDir, key+storyboardSuffix+".jpg")
+}
+
+func (m *ThumbnailManager) storyboardFromDisk(path string) *storyboard {
+	cachePath := m.storyboardCachePath(path)
+	if cachePath == "" {
+		return nil
+	}
+	sheet, err := loadImage(cachePath)
+	if err != nil {
+		return nil
+	}
+	return newStoryboardFromSheet(sheet)
+}
+
+func (m *ThumbnailManager) generateStoryboard(path string) *storyboard {
+	n := m.Options().StoryboardFrames
+	if n <= 0 {
+		n = defaultStoryboardFrames
+	}
+
+	uri := storage.NewFileURI(path)
+	duration, err := m.getVideoDuration(uri)
+	if err != nil || duration <= 0 {
+		return nil
+	}
+
+	sheet := image.NewRGBA(image.Rect(0, 0, n*storyboardFrameSize, storyboardFrameSize))
+	draw.Draw(sheet, sheet.Bounds(), &image.Uniform{image.Black}, image.Point{}, draw.Src)
+
+	extracted := 0
+	for i := range n {
+		// Sample the centre of each slot so the first and last frames avoid fades.
+		at := duration * time.Duration(2*i+1) / time.Duration(2*n)
+		frame, err := m.extractVideoFrame(uri, at)
+		if err != nil {
+			continue
+		}
+		cell := letterboxImage(frame, storyboardFrameSize)
+		if cell == nil {
+			continue
+		}
+		x := i * storyboardFrameSize
+		draw.Draw(sheet, image.Rect(x, 0, x+storyboardFrameSize, storyboardFrameSize), cell, image.Point{}, draw.Src)
+		extracted++
+	}
+	if extracted == 0 {
+		return nil
+	}
+
+	if cachePath := m.storyboardCachePath(path); cachePath != "" {
+		if f, err := os.Create(cachePath); err == nil {
+			_ = jpeg.Encode(f, sheet, &jpeg.Options{Quality: 80})
+			f.Close()
+		}
+	}
+
+	return newStoryboardFromSheet(sheet)
+}
@@ -0,0 +1,303 @@
+package dialog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	// textPreviewMaxBytes caps how much of a file is read for the text preview.
+	textPreviewMaxBytes = 64 * 1024
+	// textPreviewMaxLines caps how many lines are shown.
+	textPreviewMaxLines = 300
+	textPreviewTabWidth = 4
+)
+
+// readTextPreview reads the start of a text file and decodes it for display.
+// truncated is true when the file has more than was returned.
+func readTextPreview(u fyne.URI) (text string, truncated bool, err error) {
+	var r io.ReadCloser
+	if u.Scheme() == "file" {
+		r, err = os.Open(u.Path())
+	} else {
+		r, err = storage.Reader(u)
+	}
+	if err != nil {
+		return "", false, err
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(io.LimitReader(r, textPreviewMaxBytes+1))
+	if err != nil {
+		return "", false, err
+	}
+	if len(data) > textPreviewMaxBytes {
+		data = data[:textPreviewMaxBytes]
+		truncated = true
+	}
+
+	text, cut := limitLines(decodeText(data), textPreviewMaxLines)
+	return text, truncated || cut, nil
+}
+
+// decodeText converts UTF-8 or byte order marked UTF-16 data to a string with
+// normalised line endings and tabs expanded to spaces.
+func decodeText(data []byte) string {
+	var text string
+	switch {
+	case bytes.HasPrefix(data, []byte{0xef, 0xbb, 0xbf}):
+		text = string(data[3:])
+	case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		text = decodeUTF16(data[2:], binary.BigEndian)
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+		text = decodeUTF16(data[2:], binary.LittleEndian)
+	default:
+		text = string(data)
+	}
+
+	text = strings.ToValidUTF8(text, "�")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	return strings.ReplaceAll(text, "\t", strings.Repeat(" ", textPreviewTabWidth))
+}
+
+func decodeUTF16(data []byte, order binary.ByteOrder) string {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[i*2:])
+	}
+	return string(utf16.Decode(units))
+}
+
+// limitLines returns at most max lines of text, reporting whether any were dropped.
+func limitLines(text string, max int) (string, bool) {
+	idx := 0
+	for range max {
+		next := strings.IndexByte(text[idx:], '\n')
+		if next < 0 {
+			return text, false
+		}
+		idx += next + 1
+	}
+	if idx >= len(text) {
+		return text, false
+	}
+	return text[:idx], true
+}
+
+// syntaxLanguage describes just enough of a language to colour comments,
+// strings, numbers and keywords.
+type syntaxLanguage struct {
+	lineComments []string
+	blockComment [2]string
+	quotes       string
+	keywords     map[string]bool
+}
+
+func keywordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+var (
+	goSyntax = &syntaxLanguage{
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'`",
+		keywords: keywordSet(`break case chan const continue default defer else fallthrough for func go goto if
+			import interface map package range return select struct switch type var true false nil iota`),
+	}
+	cSyntax = &syntaxLanguage{
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'`",
+		keywords: keywordSet(`auto break case catch char class const continue default delete do double else enum
+			export extends false final float fn for function if impl import int interface let long match mod
+			mut namespace new null override package private protected pub public return self short static
+			struct super switch this throw true try type typedef undefined unsigned use var void while`),
+	}
+	pythonSyntax = &syntaxLanguage{
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+		keywords: keywordSet(`and as assert async await break class continue def del elif else except False
+			finally for from global if import in is lambda None nonlocal not or pass raise return True try
+			while with yield`),
+	}
+	shellSyntax = &syntaxLanguage{
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+		keywords: keywordSet(`case do done elif else esac export fi for function if in local readonly
+			return then until while`),
+	}
+	configSyntax = &syntaxLanguage{
+		lineComments: []string{"#", ";"},
+		quotes:       "\"'",
+		keywords:     keywordSet("true false yes no on off null"),
+	}
+	jsonSyntax = &syntaxLanguage{
+		quotes:   "\"",
+		keywords: keywordSet("true false null"),
+	}
+	markupSyntax = &syntaxLanguage{
+		blockComment: [2]string{"<!--", "-->"},
+		quotes:       "\"'",
+	}
+)
+
+var syntaxByExtension = map[string]*syntaxLanguage{
+	".go": goSyntax,
+	".c":  cSyntax, ".h": cSyntax, ".cc": cSyntax, ".cpp": cSyntax, ".hpp": cSyntax, ".cs": cSyntax,
+	".java": cSyntax, ".kt": cSyntax, ".swift": cSyntax, ".rs": cSyntax, ".dart": cSyntax,
+	".js": cSyntax, ".mjs": cSyntax, ".jsx": cSyntax, ".ts": cSyntax, ".tsx": cSyntax,
+	".py": pythonSyntax,
+	".sh": shellSyntax, ".bash": shellSyntax, ".zsh": shellSyntax,
+	".yaml": configSyntax, ".yml": configSyntax, ".toml": configSyntax, ".ini": configSyntax,
+	".conf": configSyntax, ".cfg": configSyntax, ".env": configSyntax, ".properties": configSyntax,
+	".json": jsonSyntax,
+	".xml":  markupSyntax, ".html": markupSyntax, ".htm": markupSyntax, ".svg": markupSyntax,
+}
+
+// syntaxForFile picks a language from the extension, or the shebang line of scripts.
+func syntaxForFile(name, text string) *syntaxLanguage {
+	if lang, ok := syntaxByExtension[strings.ToLower(filepath.Ext(name))]; ok {
+		return lang
+	}
+	if first, _, _ := strings.Cut(text, "\n"); strings.HasPrefix(first, "#!") {
+		if strings.Contains(first, "python") {
+			return pythonSyntax
+		}
+		return shellSyntax
+	}
+	return nil
+}
+
+// highlightText splits text into monospace segments coloured by token kind.
+// Without a language the text is returned as a single plain segment.
+func highlightText(text string, lang *syntaxLanguage) []widget.RichTextSegment {
+	if lang == nil {
+		return []widget.RichTextSegment{codeSegment(text, theme.ColorNameForeground)}
+	}
+
+	var segments []widget.RichTextSegment
+	var current strings.Builder
+	currentColor := theme.ColorNameForeground
+	emit := func(s string, color fyne.ThemeColorName) {
+		if s == "" {
+			return
+		}
+		if color != currentColor && current.Len() > 0 {
+			segments = append(segments, codeSegment(current.String(), currentColor))
+			current.Reset()
+		}
+		currentColor = color
+		current.WriteString(s)
+	}
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+
+		if prefix := lang.lineCommentAt(rest); prefix != "" {
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			emit(rest[:end], theme.ColorNamePlaceHolder)
+			i += end
+			continue
+		}
+		if open := lang.blockComment[0]; open != "" && strings.HasPrefix(rest, open) {
+			end := strings.Index(rest[len(open):], lang.blockComment[1])
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += len(open) + len(lang.blockComment[1])
+			}
+			emit(rest[:end], theme.ColorNamePlaceHolder)
+			i += end
+			continue
+		}
+
+		c := rest[0]
+		switch {
+		case strings.IndexByte(lang.quotes, c) >= 0:
+			end := stringLiteralEnd(rest)
+			emit(rest[:end], theme.ColorNameSuccess)
+			i += end
+		case c >= '0' && c <= '9':
+			end := 1
+			for end < len(rest) && (isWordByte(rest[end]) || rest[end] == '.') {
+				end++
+			}
+			emit(rest[:end], theme.ColorNameWarning)
+			i += end
+		case isWordByte(c):
+			end := 1
+			for end < len(rest) && isWordByte(rest[end]) {
+				end++
+			}
+			color := theme.ColorNameForeground
+			if lang.keywords[rest[:end]] {
+				color = theme.ColorNamePrimary
+			}
+			emit(rest[:end], color)
+			i += end
+		default:
+			emit(rest[:1], theme.ColorNameForeground)
+			i++
+		}
+	}
+	if current.Len() > 0 {
+		segments = append(segments, codeSegment(current.String(), currentColor))
+	}
+	return segments
+}
+
+func (l *syntaxLanguage) lineCommentAt(s string) string {
+	for _, prefix := range l.lineComments {
+		if strings.HasPrefix(s, prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// stringLiteralEnd returns the length of the quoted literal at the start of s.
+// Literals end at the closing quote or, except for backquotes, the line end.
+func stringLiteralEnd(s string) int {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quote != '`':
+			i++
+		case s[i] == quote:
+			return i + 1
+		case s[i] == '\n' && quote != '`':
+			return i
+		}
+	}
+	return len(s)
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 0x80 || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
+
+func codeSegment(text string, color fyne.ThemeColorName) *widget.TextSegment {
+	style := widget.RichTextStyleCodeInline
+	style.ColorName = color
+	return &widget.TextSegment{Text: text, Style: style}
+}
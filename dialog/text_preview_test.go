@@ -0,0 +1,101 @@
+package dialog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+func TestDecodeText(t *testing.T) {
+	cases := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"utf8", []byte("a\r\nb\tc"), "a\nb    c"},
+		{"utf8 bom", []byte("\xef\xbb\xbfhé"), "hé"},
+		{"utf16le", []byte{0xff, 0xfe, 'h', 0, 'i', 0, 0xe9, 0}, "hié"},
+		{"utf16be", []byte{0xfe, 0xff, 0, 'h', 0, 'i'}, "hi"},
+		{"invalid", []byte{'a', 0xff, 'b'}, "a�b"},
+	}
+	for _, tc := range cases {
+		if got := decodeText(tc.data); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestReadTextPreview_CapsLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "long.txt")
+	if err := os.WriteFile(path, []byte(strings.Repeat("line\n", textPreviewMaxLines+10)), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	text, truncated, err := readTextPreview(storage.NewFileURI(path))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !truncated {
+		t.Error("expected preview to be truncated")
+	}
+	if n := strings.Count(text, "\n"); n != textPreviewMaxLines {
+		t.Errorf("expected %d lines, got %d", textPreviewMaxLines, n)
+	}
+}
+
+func TestDetectFileType_SniffsText(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "deploy")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho hi\n"), 0o755); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	binary := filepath.Join(dir, "blob.txt")
+	if err := os.WriteFile(binary, []byte{0x00, 0x01, 0x02, 'a'}, 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	if ft := (&ThumbnailManager{}).detectFileType(storage.NewFileURI(script)); !ft.text || ft.mime != "text/plain" {
+		t.Errorf("expected extensionless script to be text, got %+v", ft)
+	}
+	if ft := (&ThumbnailManager{}).detectFileType(storage.NewFileURI(binary)); ft.text {
+		t.Errorf("expected binary content not to be text, got %+v", ft)
+	}
+	if syntaxForFile("deploy", "#!/usr/bin/env python3\n") != pythonSyntax {
+		t.Error("expected shebang to select python")
+	}
+}
+
+func TestHighlightText(t *testing.T) {
+	src := "func main() {\n\t// hi\n\ts := \"x\" + 42\n}"
+	colors := map[string]fyne.ThemeColorName{}
+	var joined strings.Builder
+	for _, seg := range highlightText(src, goSyntax) {
+		ts := seg.(*widget.TextSegment)
+		if !ts.Style.TextStyle.Monospace {
+			t.Fatal("expected monospace segments")
+		}
+		colors[strings.TrimSpace(ts.Text)] = ts.Style.ColorName
+		joined.WriteString(ts.Text)
+	}
+
+	if joined.String() != src {
+		t.Fatalf("segments do not reassemble the source: %q", joined.String())
+	}
+	want := map[string]fyne.ThemeColorName{
+		"func":  theme.ColorNamePrimary,
+		"// hi": theme.ColorNamePlaceHolder,
+		`"x"`:   theme.ColorNameSuccess,
+		"42":    theme.ColorNameWarning,
+	}
+	for text, color := range want {
+		if colors[text] != color {
+			t.Errorf("%q: got colour %q, want %q", text, colors[text], color)
+		}
+	}
+}
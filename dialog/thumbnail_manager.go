@@ -2,16 +2,20 @@ package dialog
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
 	"image"
+	_ "image/gif"
 	"image/jpeg"
 	_ "image/png"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"sort"
 	"strings"
 	"sync"
@@ -19,21 +23,98 @@ import (
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/storage/repository"
 	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
 )
 
 type thumbnailRequest struct {
 	uri      fyne.URI
 	callback func(*canvas.Image)
+	// failed, if set, is called instead when no thumbnail is produced.
+	failed func()
 }
 
+// VideoFrameStrategy controls where video thumbnails are taken from.
+type VideoFrameStrategy int
+
+const (
+	// VideoFrameMiddle samples frames around the middle of the video, centre
+	// first, and uses the first one with plenty of detail, or failing that the
+	// one with the most detail.
+	VideoFrameMiddle VideoFrameStrategy = iota
+	// VideoFrameFirstNonBlack uses the earliest sampled frame that is not a
+	// black slate or a fade.
+	VideoFrameFirstNonBlack
+	// VideoFrameFixedOffset uses the frame at ThumbnailOptions.VideoFrameOffset,
+	// moving forward if that frame is black.
+	VideoFrameFixedOffset
+)
+
+// ThumbnailOptions configures how a ThumbnailManager generates thumbnails.
+type ThumbnailOptions struct {
+	VideoFrameStrategy VideoFrameStrategy
+	// VideoFrameOffset is the seek position used by VideoFrameFixedOffset.
+	VideoFrameOffset time.Duration
+	// VideoFrameSamples is the number of candidate frames extracted per video.
+	// Zero means defaultVideoFrameSamples.
+	VideoFrameSamples int
+	// FolderMosaic replaces the folder icon in grid view with a 2×2 mosaic of
+	// the first images or videos inside the folder.
+	FolderMosaic bool
+	// FolderMosaicMaxEntries skips mosaics for folders with more entries than
+	// this. Zero means defaultFolderMosaicMaxEntries.
+	FolderMosaicMaxEntries int
+	// StoryboardFrames is the number of frames in the grid view hover preview
+	// of a video. Zero means defaultStoryboardFrames.
+	StoryboardFrames int
+}
+
+const (
+	defaultVideoFrameSamples = 5
+	// maxConcurrentPreviews is how many LoadPreview renders run at once.
+	maxConcurrentPreviews = 2
+)
+
 type ThumbnailManager struct {
 	cache      sync.Map // map[string]*canvas.Image
+	info       sync.Map // map[string]mediaInfo
+	meta       sync.Map // map[string]mediaInfo, read without rendering
 	requests   []thumbnailRequest
 	reqLock    sync.Mutex
 	reqCond    *sync.Cond
 	ffmpegPath string
 	cacheDir   string
+
+	optsLock sync.RWMutex
+	opts     ThumbnailOptions
+
+	storyboards storyboardCache
+	// storyboardWaiters holds the callbacks of storyboards being generated.
+	storyboardLock    sync.Mutex
+	storyboardWaiters map[string][]func(*storyboard)
+
+	uriKeys sync.Map // map[string]string, cache keys of non-file URIs
+
+	previewOnce sync.Once
+	previews    chan struct{}
+
+	// fileTypes holds detection results by URI; see fileTypeCache.
+	fileTypesOnce sync.Once
+	fileTypes     *lruCache[fileTypeEntry]
+	typeSlots     chan struct{}
+	// typeWaiters holds the callbacks of files being detected in the background.
+	typeLock    sync.Mutex
+	typeWaiters map[string][]func(fileType)
+}
+
+// ThumbnailKeyer can be implemented by a storage repository to provide cheap,
+// stable thumbnail cache keys, for example from an ETag or revision id.
+// The key must change whenever the content at the URI changes.
+// Without it, thumbnails of non-file URIs are keyed by a hash of their content.
+type ThumbnailKeyer interface {
+	ThumbnailKey(fyne.URI) (string, error)
 }
 
 var (
@@ -53,84 +134,134 @@ func SetFFmpegPath(path string) {
 	}
 }
 
+// GetThumbnailManager returns the manager shared by dialogs that are not
+// given their own.
 func GetThumbnailManager() *ThumbnailManager {
 	once.Do(func() {
-		ffmpeg := fyne.CurrentApp().Preferences().String(ffmpegPathKey)
-		instance = &ThumbnailManager{
-			requests:   make([]thumbnailRequest, 0, 100),
-			ffmpegPath: ffmpeg,
-		}
-		instance.reqCond = sync.NewCond(&instance.reqLock)
-
-		// Setup persistent cache
-		if userCache, err := os.UserCacheDir(); err == nil {
-			instance.cacheDir = filepath.Join(userCache, "xfilepicker")
-			_ = os.MkdirAll(instance.cacheDir, 0755)
-			go instance.cleanupCache()
-		}
-
-		// Start workers
-		for range 4 {
-			go instance.worker()
-		}
+		instance = NewThumbnailManager(ThumbnailOptions{})
 	})
 	return instance
 }
 
+// NewThumbnailManager creates a thumbnail manager with its own memory cache
+// and workers, sharing the disk cache and FFmpeg path of the default one.
+// It can be passed to a dialog with Options.ThumbnailManager. FFmpeg is
+// used from the path saved by SetFFmpegPath when the manager is created.
+func NewThumbnailManager(opts ThumbnailOptions) *ThumbnailManager {
+	m := &ThumbnailManager{
+		requests:   make([]thumbnailRequest, 0, 100),
+		ffmpegPath: fyne.CurrentApp().Preferences().String(ffmpegPathKey),
+		opts:       opts,
+	}
+	m.reqCond = sync.NewCond(&m.reqLock)
+
+	// Setup persistent cache
+	if userCache, err := os.UserCacheDir(); err == nil {
+		m.cacheDir = filepath.Join(userCache, "xfilepicker")
+		_ = os.MkdirAll(m.cacheDir, 0755)
+		go m.cleanupCache()
+	}
+
+	// Start workers
+	for range 4 {
+		go m.worker()
+	}
+	return m
+}
+
+// SetOptions replaces the thumbnail generation options.
+// Thumbnails that are already cached are not regenerated.
+func (m *ThumbnailManager) SetOptions(opts ThumbnailOptions) {
+	m.optsLock.Lock()
+	m.opts = opts
+	m.optsLock.Unlock()
+}
+
+// Options returns the current thumbnail generation options.
+func (m *ThumbnailManager) Options() ThumbnailOptions {
+	m.optsLock.RLock()
+	defer m.optsLock.RUnlock()
+	return m.opts
+}
+
 // LoadMemoryOnly retrieves a thumbnail from memory cache only.
+// The id is the file path for local files, see thumbnailID.
 // Returns nil if not in memory.
-func (m *ThumbnailManager) LoadMemoryOnly(path string) *canvas.Image {
-	if cached, ok := m.cache.Load(path); ok {
+func (m *ThumbnailManager) LoadMemoryOnly(id string) *canvas.Image {
+	if cached, ok := m.cache.Load(id); ok {
 		return cached.(*canvas.Image)
 	}
 	return nil
 }
 
+// thumbnailID is the memory cache key for a URI: the path for local files
+// and the full URI string for other repositories.
+func thumbnailID(uri fyne.URI) string {
+	if uri.Scheme() == "file" {
+		return uri.Path()
+	}
+	return uri.String()
+}
+
 func (m *ThumbnailManager) Load(uri fyne.URI, callback func(*canvas.Image)) {
-	if uri == nil || uri.Scheme() != "file" {
-		// Not a local file, or nil
+	m.load(uri, callback, nil)
+}
+
+// load is Load with a failed callback, called if uri cannot be thumbnailed or
+// its request is dropped from the queue.
+func (m *ThumbnailManager) load(uri fyne.URI, callback func(*canvas.Image), failed func()) {
+	if failed == nil {
+		failed = func() {}
+	}
+	if uri == nil || !m.canThumbnail(uri) {
+		// Not a supported format
+		failed()
 		return
 	}
 
-	ext := strings.ToLower(filepath.Ext(uri.Path()))
-	if !isSupportedImage(ext) && !isSupportedVideo(ext) {
-		// Not a supported format
+	if isLocalFolder(uri) {
+		m.loadFolderMosaic(uri, callback)
 		return
 	}
 
-	path := uri.Path()
-	if cached, ok := m.cache.Load(path); ok {
+	id := thumbnailID(uri)
+	if cached, ok := m.cache.Load(id); ok {
 		callback(cached.(*canvas.Image))
 		return
 	}
 
 	// Check disk cache before queuing
-	if m.cacheDir != "" {
-		if key, err := m.generateCacheKey(path); err == nil {
-			cachePath := filepath.Join(m.cacheDir, key+".jpg")
-			if _, err := os.Stat(cachePath); err == nil {
-				if img, err := loadImage(cachePath); err == nil {
-					canvasImg := canvas.NewImageFromImage(img)
-					canvasImg.FillMode = canvas.ImageFillContain
-					m.cache.Store(path, canvasImg)
-					callback(canvasImg)
-					return
-				}
-			}
-		}
+	if canvasImg := m.loadFromDisk(uri); canvasImg != nil {
+		callback(canvasImg)
+		return
 	}
 
 	// LIFO Queue Logic
 	m.reqLock.Lock()
 	// If queue is full, drop the OLDEST request (at index 0)
 	// Keeps the set of pending requests small and relevant
+	var dropped thumbnailRequest
 	if len(m.requests) >= 100 {
 		// Drop first
+		dropped = m.requests[0]
 		m.requests = m.requests[1:]
 	}
-	m.requests = append(m.requests, thumbnailRequest{uri: uri, callback: callback})
+	m.requests = append(m.requests, thumbnailRequest{uri: uri, callback: callback, failed: failed})
 	m.reqCond.Signal()
 	m.reqLock.Unlock()
+
+	if dropped.failed != nil {
+		dropped.failed()
+	}
+}
+
+// isLocalFolder reports whether uri is a folder on the local file system.
+func isLocalFolder(uri fyne.URI) bool {
+	if uri.Scheme() != "file" {
+		return false
+	}
+	stat, err := os.Stat(uri.Path())
+	return err == nil && stat.IsDir()
 }
 
 // PrewarmDirectory attempts to load thumbnails from disk cache into memory in the background.
@@ -141,36 +272,102 @@ func (m *ThumbnailManager) PrewarmDirectory(uris []fyne.URI) {
 
 	go func() {
 		for _, uri := range uris {
+			// Keys for other repositories may need the whole content, so only
+			// local files are prewarmed.
 			if uri.Scheme() != "file" {
 				continue
 			}
-			path := uri.Path()
 
 			// Skip if already in memory
-			if _, ok := m.cache.Load(path); ok {
+			if _, ok := m.cache.Load(thumbnailID(uri)); ok {
 				continue
 			}
-
-			// Generate key (this involves Stat() and reading 32KB, but it's background)
-			key, err := m.generateCacheKey(path)
-			if err != nil {
+			if !m.canThumbnail(uri) {
 				continue
 			}
 
-			cachePath := filepath.Join(m.cacheDir, key+".jpg")
-			if _, err := os.Stat(cachePath); err == nil {
-				if img, err := loadImage(cachePath); err == nil {
-					canvasImg := canvas.NewImageFromImage(img)
-					canvasImg.FillMode = canvas.ImageFillContain
-					m.cache.Store(path, canvasImg)
-				}
-			}
+			// Generating the key involves Stat() and reading 32KB, but it's background
+			m.loadFromDisk(uri)
 			// Small sleep to avoid I/O spikes
 			time.Sleep(5 * time.Millisecond)
 		}
 	}()
 }
 
+// canThumbnail reports whether uri is a supported media file, or a local
+// folder while folder mosaics are enabled.
+func (m *ThumbnailManager) canThumbnail(uri fyne.URI) bool {
+	if uri.Scheme() != "file" {
+		// Cover art and mosaics need random access to local files.
+		ft := m.detectFileType(uri)
+		return ft.isImage() || ft.isVideo()
+	}
+	if stat, err := os.Stat(uri.Path()); err == nil && stat.IsDir() {
+		return m.Options().FolderMosaic
+	}
+	ft := m.detectFileType(uri)
+	return ft.isImage() || ft.isVideo() || ft.isAudio()
+}
+
+// loadFromDisk promotes a disk cached thumbnail, and its metadata, into memory.
+// Returns nil if the thumbnail is not cached on disk, or if finding it would
+// mean reading the whole content; see renderThumbnail.
+func (m *ThumbnailManager) loadFromDisk(uri fyne.URI) *canvas.Image {
+	if m.cacheDir == "" {
+		return nil
+	}
+	key, err := m.knownCacheKey(uri)
+	if err != nil {
+		return nil
+	}
+
+	return m.loadCached(thumbnailID(uri), key)
+}
+
+// loadCached promotes the disk cache entry key into memory under id.
+func (m *ThumbnailManager) loadCached(id, key string) *canvas.Image {
+	if m.cacheDir == "" {
+		return nil
+	}
+	cachePath := filepath.Join(m.cacheDir, key+".jpg")
+	if _, err := os.Stat(cachePath); err != nil {
+		return nil
+	}
+	img, err := loadImage(cachePath)
+	if err != nil {
+		return nil
+	}
+
+	if info, err := readMediaInfo(filepath.Join(m.cacheDir, key+".json")); err == nil {
+		m.info.Store(id, info)
+	}
+	canvasImg := canvas.NewImageFromImage(img)
+	canvasImg.FillMode = canvas.ImageFillContain
+	m.cache.Store(id, canvasImg)
+	return canvasImg
+}
+
+// saveToDisk writes a thumbnail and its metadata to the disk cache.
+func (m *ThumbnailManager) saveToDisk(key string, img image.Image, info mediaInfo) {
+	if m.cacheDir == "" {
+		return
+	}
+	f, err := os.Create(filepath.Join(m.cacheDir, key+".jpg"))
+	if err == nil {
+		_ = jpeg.Encode(f, img, &jpeg.Options{Quality: 85})
+		f.Close()
+	}
+	_ = writeMediaInfo(filepath.Join(m.cacheDir, key+".json"), info)
+}
+
+// loadMediaInfo returns the metadata recorded for a thumbnail in memory.
+func (m *ThumbnailManager) loadMediaInfo(id string) (mediaInfo, bool) {
+	if info, ok := m.info.Load(id); ok {
+		return info.(mediaInfo), true
+	}
+	return mediaInfo{}, false
+}
+
 func (m *ThumbnailManager) worker() {
 	for {
 		m.reqLock.Lock()
@@ -183,121 +380,279 @@ func (m *ThumbnailManager) worker() {
 		m.requests = m.requests[:lastIdx]
 		m.reqLock.Unlock()
 
-		path := req.uri.Path()
-
-		if cached, ok := m.cache.Load(path); ok {
-			req.callback(cached.(*canvas.Image))
-			continue
+		if canvasImg := m.renderThumbnail(req.uri); canvasImg != nil {
+			req.callback(canvasImg)
+		} else if req.failed != nil {
+			req.failed()
 		}
+	}
+}
 
-		var img image.Image
-		var err error
+// renderThumbnail returns the memory cached thumbnail for uri, or generates it
+// and stores it in the memory and disk caches. Returns nil on failure.
+func (m *ThumbnailManager) renderThumbnail(uri fyne.URI) *canvas.Image {
+	id := thumbnailID(uri)
+	if cached, ok := m.cache.Load(id); ok {
+		return cached.(*canvas.Image)
+	}
 
-		ext := strings.ToLower(filepath.Ext(path))
-		if isSupportedImage(ext) {
-			img, err = loadImage(path)
-		} else if isSupportedVideo(ext) {
-			img, err = m.generateVideoThumbnail(path)
+	// Other repositories are read once, here in the worker: the local copy
+	// provides the content key for the disk cache and is rendered from.
+	src := uri
+	if uri.Scheme() != "file" {
+		local, release, err := m.localCopy(uri)
+		if err != nil {
+			return nil
 		}
-
-		if err != nil || img == nil {
-			continue
+		defer release()
+		if canvasImg := m.loadFromDisk(uri); canvasImg != nil {
+			return canvasImg
 		}
+		src = local
+	}
+
+	img, info, err := m.sourceImage(src)
+	if err != nil || img == nil {
+		return nil
+	}
 
-		// Resize and letterbox
-		// Use fileIconSize * 2 for high density displays (128px)
-		const targetSize = 128
-		dst := image.NewRGBA(image.Rect(0, 0, targetSize, targetSize))
+	// Use fileIconSize * 2 for high density displays (128px)
+	const targetSize = 128
+	dst := letterboxImage(img, targetSize)
+	if dst == nil {
+		return nil
+	}
 
-		// Fill with black
-		draw.Draw(dst, dst.Bounds(), &image.Uniform{image.Black}, image.Point{}, draw.Src)
+	canvasImg := canvas.NewImageFromImage(dst)
+	canvasImg.FillMode = canvas.ImageFillContain
 
-		// Calculate scaled dimensions
-		srcBounds := img.Bounds()
-		srcW, srcH := srcBounds.Dx(), srcBounds.Dy()
-		var scaledW, scaledH int
+	m.info.Store(id, info)
+	m.cache.Store(id, canvasImg)
 
-		// Avoid division by zero
-		if srcW == 0 || srcH == 0 {
-			continue
+	// Save to disk cache
+	if m.cacheDir != "" {
+		if key, err := m.cacheKey(uri); err == nil {
+			m.saveToDisk(key, dst, info)
 		}
+	}
+
+	return canvasImg
+}
 
-		ratio := float64(srcW) / float64(srcH)
-		if ratio > 1 {
-			// Landscape or square
-			scaledW = targetSize
-			scaledH = int(float64(targetSize) / ratio)
-		} else {
-			// Portrait
-			scaledH = targetSize
-			scaledW = int(float64(targetSize) * ratio)
+// sourceImage produces the full size picture for uri, and the metadata found
+// along the way: the decoded image, a video frame, cover art or a folder mosaic.
+func (m *ThumbnailManager) sourceImage(uri fyne.URI) (image.Image, mediaInfo, error) {
+	var img image.Image
+	var info mediaInfo
+	var err error
+
+	local := uri.Scheme() == "file"
+	if stat, statErr := os.Stat(uri.Path()); local && statErr == nil && stat.IsDir() {
+		img, err = m.generateFolderMosaic(uri.Path())
+	} else if ft := m.detectFileType(uri); ft.isImage() {
+		var format string
+		img, format, err = decodeImageURI(uri)
+		if err == nil {
+			info = imageMediaInfo(img, format)
+			m.readImageEXIF(uri, &info)
 		}
+	} else if ft.isVideo() {
+		// ffmpeg reads a stream once per frame, so other URIs are copied first.
+		src, release, copyErr := m.localCopy(uri)
+		if copyErr != nil {
+			return nil, info, copyErr
+		}
+		defer release()
+		info, _ = m.probeVideo(src)
+		img, err = m.generateVideoThumbnail(src, info.Duration)
+	} else if local && ft.isAudio() {
+		img, err = loadCoverArt(uri.Path())
+		info = mediaInfo{Format: audioFormatNames[ft.mime]}
+	} else {
+		err = fmt.Errorf("no preview available for %s", uri.Name())
+	}
+	return img, info, err
+}
 
-		// Center
-		xBase := (targetSize - scaledW) / 2
-		yBase := (targetSize - scaledH) / 2
-		targetRect := image.Rect(xBase, yBase, xBase+scaledW, yBase+scaledH)
+// LoadPreview renders a larger preview of uri letterboxed to size pixels, for
+// example for the preview pane. Previews are not cached; the metadata is
+// recorded like for thumbnails. The callback runs on a background goroutine
+// and is not called if no preview can be produced.
+func (m *ThumbnailManager) LoadPreview(uri fyne.URI, size int, callback func(*canvas.Image)) {
+	m.LoadPreviewContext(context.Background(), uri, size, callback)
+}
 
-		// Use ApproxBiLinear for speed
-		draw.ApproxBiLinear.Scale(dst, targetRect, img, srcBounds, draw.Over, nil)
+// LoadPreviewContext is LoadPreview for previews that may become unwanted,
+// such as those of an item that has lost focus. Only a few previews are
+// rendered at a time; one whose ctx is cancelled before its turn is skipped,
+// and its callback is not called once ctx is done.
+func (m *ThumbnailManager) LoadPreviewContext(ctx context.Context, uri fyne.URI, size int, callback func(*canvas.Image)) {
+	if uri == nil || !m.canThumbnail(uri) {
+		return
+	}
+
+	go func() {
+		slots := m.previewSlots()
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+		case <-ctx.Done():
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		img, info, err := m.sourceImage(uri)
+		if err != nil || img == nil {
+			return
+		}
+		dst := letterboxImage(img, size)
+		if dst == nil {
+			return
+		}
+		m.info.Store(thumbnailID(uri), info)
+		if ctx.Err() != nil {
+			return
+		}
 
 		canvasImg := canvas.NewImageFromImage(dst)
 		canvasImg.FillMode = canvas.ImageFillContain
+		callback(canvasImg)
+	}()
+}
 
-		m.cache.Store(path, canvasImg)
-
-		// Save to disk cache
-		if m.cacheDir != "" {
-			if key, err := m.generateCacheKey(path); err == nil {
-				cachePath := filepath.Join(m.cacheDir, key+".jpg")
-				f, err := os.Create(cachePath)
-				if err == nil {
-					_ = jpeg.Encode(f, dst, &jpeg.Options{Quality: 85})
-					f.Close()
-				}
-			}
-		}
+// previewSlots limits how many previews render at once, as each may run FFmpeg.
+func (m *ThumbnailManager) previewSlots() chan struct{} {
+	m.previewOnce.Do(func() {
+		m.previews = make(chan struct{}, maxConcurrentPreviews)
+	})
+	return m.previews
+}
+
+// letterboxImage scales img to fit a size×size square, centred on black.
+// It returns nil for empty images.
+func letterboxImage(img image.Image, size int) *image.RGBA {
+	srcBounds := img.Bounds()
+	srcW, srcH := srcBounds.Dx(), srcBounds.Dy()
+	// Avoid division by zero
+	if srcW == 0 || srcH == 0 {
+		return nil
+	}
 
-		req.callback(canvasImg)
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+
+	// Fill with black
+	draw.Draw(dst, dst.Bounds(), &image.Uniform{image.Black}, image.Point{}, draw.Src)
+
+	// Calculate scaled dimensions
+	var scaledW, scaledH int
+	ratio := float64(srcW) / float64(srcH)
+	if ratio > 1 {
+		// Landscape or square
+		scaledW = size
+		scaledH = int(float64(size) / ratio)
+	} else {
+		// Portrait
+		scaledH = size
+		scaledW = int(float64(size) * ratio)
 	}
+
+	// Center
+	xBase := (size - scaledW) / 2
+	yBase := (size - scaledH) / 2
+	targetRect := image.Rect(xBase, yBase, xBase+scaledW, yBase+scaledH)
+
+	// Use ApproxBiLinear for speed
+	draw.ApproxBiLinear.Scale(dst, targetRect, img, srcBounds, draw.Over, nil)
+	return dst
 }
 
 func loadImage(path string) (image.Image, error) {
+	img, _, err := decodeImageFile(path)
+	return img, err
+}
+
+// decodeImageURI decodes an image from any storage repository and reports its format name.
+func decodeImageURI(uri fyne.URI) (image.Image, string, error) {
+	if uri.Scheme() == "file" {
+		return decodeImageFile(uri.Path())
+	}
+	r, err := storage.Reader(uri)
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Close()
+
+	return image.Decode(r)
+}
+
+// decodeImageFile decodes an image and reports its format name.
+func decodeImageFile(path string) (image.Image, string, error) {
 	f, err := os.Open(path)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer f.Close()
 
-	img, _, err := image.Decode(f)
-	return img, err
+	return image.Decode(f)
 }
 
-func (m *ThumbnailManager) generateVideoThumbnail(path string) (image.Image, error) {
-
-	// 1. Get duration
-	duration, err := m.getVideoDuration(path)
-	if err != nil {
-		// Fallback to 1 second if duration parsing fails
+func (m *ThumbnailManager) generateVideoThumbnail(uri fyne.URI, duration time.Duration) (image.Image, error) {
+	// 1. Fallback to 1 second if the duration could not be probed
+	if duration <= 0 {
 		duration = 1 * time.Second
 	}
 
-	// 2. Calculate seek time (middle)
-	seekTime := duration / 2
-	seekStr := fmt.Sprintf("%02d:%02d:%02d.%03d",
-		int(seekTime.Hours()),
-		int(seekTime.Minutes())%60,
-		int(seekTime.Seconds())%60,
-		seekTime.Milliseconds()%1000)
+	// 2. Pick candidate timestamps and score the frames found there.
+	// Landing exactly on duration/2 often hits a fade or a black slate, so we
+	// sample several positions until one is informative enough.
+	opts := m.Options()
+	candidates := videoFrameCandidates(opts, duration)
+	return pickVideoFrame(candidates, opts.VideoFrameStrategy, func(at time.Duration) (image.Image, error) {
+		return m.extractVideoFrame(uri, at)
+	})
+}
 
+// pickVideoFrame extracts the candidates in order until one is good enough
+// for the strategy, so most videos cost a single ffmpeg run. VideoFrameMiddle
+// stops at the first detailed frame and otherwise keeps the one with the most
+// detail; the other strategies stop at the first frame that is not blank.
+func pickVideoFrame(candidates []time.Duration, strategy VideoFrameStrategy, extract func(time.Duration) (image.Image, error)) (image.Image, error) {
+	var best image.Image
+	bestScore := -1.0
+	var lastErr error
+	for _, at := range candidates {
+		img, err := extract(at)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		mean, variance := frameLuminance(img)
+		if !isBlankFrame(mean, variance) && (strategy != VideoFrameMiddle || isDetailedFrame(variance)) {
+			return img, nil
+		}
+		if variance > bestScore {
+			best, bestScore = img, variance
+		}
+	}
+	if best == nil {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no frames extracted")
+		}
+		return nil, lastErr
+	}
+	return best, nil
+}
+
+// extractVideoFrame decodes a single frame at the given position using ffmpeg.
+func (m *ThumbnailManager) extractVideoFrame(uri fyne.URI, at time.Duration) (image.Image, error) {
 	// ffmpeg -ss <seek> -i <file> -vframes 1 -f image2 -
 	// Note: Putting -ss before -i is faster (input seeking) but less accurate.
 	// For thumbnails, input seeking is usually fine and much faster.
-	cmd := exec.Command(m.ffmpegPath, "-ss", seekStr, "-i", path, "-vframes", "1", "-f", "image2", "-strict", "unofficial", "-")
-	applyHiddenWindow(cmd)
 	var buf bytes.Buffer
-	cmd.Stdout = &buf
-	if err := cmd.Run(); err != nil {
+	err := m.runFFmpeg(uri, []string{"-ss", formatSeekTime(at)}, []string{"-vframes", "1", "-f", "image2", "-strict", "unofficial", "-"}, &buf, nil)
+	if err != nil {
 		return nil, err
 	}
 
@@ -305,47 +660,243 @@ func (m *ThumbnailManager) generateVideoThumbnail(path string) (image.Image, err
 	return img, err
 }
 
-func (m *ThumbnailManager) getVideoDuration(path string) (time.Duration, error) {
-	// ffmpeg -i <file> 2>&1 | grep "Duration"
-	cmd := exec.Command(m.ffmpegPath, "-i", path)
+// localCopy returns uri itself for local files. Other URIs are copied to a
+// temporary file, hashing the content for cacheKey on the way, so tools that
+// read the input several times only stream it once. release removes the copy.
+func (m *ThumbnailManager) localCopy(uri fyne.URI) (fyne.URI, func(), error) {
+	if uri.Scheme() == "file" {
+		return uri, func() {}, nil
+	}
+
+	r, err := storage.Reader(uri)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer r.Close()
+	f, err := os.CreateTemp("", "xfilepicker-*"+uri.Extension())
+	if err != nil {
+		return nil, nil, err
+	}
+	release := func() { _ = os.Remove(f.Name()) }
+
+	h := contentKeyHash(uri)
+	_, err = io.Copy(io.MultiWriter(f, h), r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	m.uriKeys.Store(uri.String(), hex.EncodeToString(h.Sum(nil)))
+	return storage.NewFileURI(f.Name()), release, nil
+}
+
+// contentKeyHash starts the hash a non-file URI is keyed by when its
+// repository has no ThumbnailKey; the content is written to it next.
+func contentKeyHash(uri fyne.URI) hash.Hash {
+	h := sha256.New()
+	h.Write([]byte(uri.String()))
+	return h
+}
+
+// runFFmpeg runs ffmpeg with uri as its input. Local files are passed by path;
+// other URIs are streamed through stdin from their storage.Reader.
+func (m *ThumbnailManager) runFFmpeg(uri fyne.URI, inputArgs, outputArgs []string, stdout, stderr io.Writer) error {
+	return m.runFFTool(m.ffmpegPath, uri, inputArgs, outputArgs, stdout, stderr)
+}
+
+// runFFTool runs an FFmpeg suite binary such as ffmpeg or ffprobe, see runFFmpeg.
+func (m *ThumbnailManager) runFFTool(bin string, uri fyne.URI, inputArgs, outputArgs []string, stdout, stderr io.Writer) error {
+	input := uri.Path()
+	var stdin io.ReadCloser
+	if uri.Scheme() != "file" {
+		r, err := storage.Reader(uri)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		stdin = r
+		input = "pipe:0"
+	}
+
+	args := append(append(append([]string{}, inputArgs...), "-i", input), outputArgs...)
+	cmd := exec.Command(bin, args...)
 	applyHiddenWindow(cmd)
-	// ffmpeg prints to stderr
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-	// Run usually fails because no output file is specified, but we get the info
-	_ = cmd.Run()
+	if stdin != nil {
+		// ffmpeg may exit before consuming all input; os/exec ignores the resulting EPIPE.
+		cmd.Stdin = stdin
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
 
-	out := stderr.String()
-	// Regex for "Duration: HH:MM:SS.mm"
-	re := regexp.MustCompile(`Duration: (\d{2}):(\d{2}):(\d{2})\.(\d{2})`)
-	matches := re.FindStringSubmatch(out)
-	if len(matches) < 5 {
-		return 0, fmt.Errorf("could not find duration in output")
+func formatSeekTime(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d:%02d.%03d",
+		int(d.Hours()),
+		int(d.Minutes())%60,
+		int(d.Seconds())%60,
+		d.Milliseconds()%1000)
+}
+
+// videoFrameCandidates returns the seek positions to try for the given strategy,
+// in the order they should be tried.
+func videoFrameCandidates(opts ThumbnailOptions, duration time.Duration) []time.Duration {
+	n := opts.VideoFrameSamples
+	if n <= 0 {
+		n = defaultVideoFrameSamples
+	}
+	if duration <= 0 {
+		return []time.Duration{0}
+	}
+
+	candidates := make([]time.Duration, 0, n)
+	switch opts.VideoFrameStrategy {
+	case VideoFrameFirstNonBlack:
+		// Evenly spaced from the start, skipping the very first frame which is
+		// usually part of a fade in.
+		for i := 1; i <= n; i++ {
+			candidates = append(candidates, duration*time.Duration(i)/time.Duration(n+1))
+		}
+	case VideoFrameFixedOffset:
+		start := opts.VideoFrameOffset
+		if start < 0 {
+			start = 0
+		}
+		if start >= duration {
+			start = duration / 2
+		}
+		step := (duration - start) / time.Duration(n)
+		for i := 0; i < n; i++ {
+			candidates = append(candidates, start+step*time.Duration(i))
+		}
+	default:
+		// Centre first, then alternate outwards within the middle 60% of the clip.
+		mid := duration / 2
+		step := duration * 3 / 10 / time.Duration(n)
+		candidates = append(candidates, mid)
+		for i := 1; len(candidates) < n; i++ {
+			candidates = append(candidates, mid-step*time.Duration(i))
+			if len(candidates) < n {
+				candidates = append(candidates, mid+step*time.Duration(i))
+			}
+		}
 	}
+	return candidates
+}
 
-	hours := 0
-	minutes := 0
-	seconds := 0
-	centiseconds := 0
+// frameLuminance returns the mean and variance of the Rec. 601 luma of img,
+// on a 0-255 scale. Large images are sampled on a coarse grid.
+func frameLuminance(img image.Image) (mean, variance float64) {
+	b := img.Bounds()
+	if b.Empty() {
+		return 0, 0
+	}
 
-	fmt.Sscanf(matches[1], "%d", &hours)
-	fmt.Sscanf(matches[2], "%d", &minutes)
-	fmt.Sscanf(matches[3], "%d", &seconds)
-	fmt.Sscanf(matches[4], "%d", &centiseconds)
+	const maxSamples = 64
+	stepX := max(b.Dx()/maxSamples, 1)
+	stepY := max(b.Dy()/maxSamples, 1)
+
+	var sum, sumSq float64
+	count := 0
+	for y := b.Min.Y; y < b.Max.Y; y += stepY {
+		for x := b.Min.X; x < b.Max.X; x += stepX {
+			r, g, bl, _ := img.At(x, y).RGBA()
+			l := (0.299*float64(r) + 0.587*float64(g) + 0.114*float64(bl)) / 257
+			sum += l
+			sumSq += l * l
+			count++
+		}
+	}
 
-	duration := time.Duration(hours)*time.Hour +
-		time.Duration(minutes)*time.Minute +
-		time.Duration(seconds)*time.Second +
-		time.Duration(centiseconds*10)*time.Millisecond
+	mean = sum / float64(count)
+	variance = sumSq/float64(count) - mean*mean
+	if variance < 0 {
+		variance = 0
+	}
+	return mean, variance
+}
 
-	return duration, nil
+// isBlankFrame reports whether a frame with the given luminance statistics is
+// a black slate, a flash to white or a flat fade.
+func isBlankFrame(mean, variance float64) bool {
+	const (
+		minVariance = 60 // roughly a standard deviation of 8 levels
+		minMean     = 16
+		maxMean     = 240
+	)
+	return variance < minVariance || mean < minMean || mean > maxMean
 }
-func isSupportedImage(ext string) bool {
-	return ext == ".jpg" || ext == ".jpeg" || ext == ".png"
+
+// isDetailedFrame reports whether a frame has enough contrast to stand for the
+// video without sampling further.
+func isDetailedFrame(variance float64) bool {
+	const minVariance = 900 // roughly a standard deviation of 30 levels
+	return variance >= minVariance
 }
 
-func isSupportedVideo(ext string) bool {
-	return ext == ".mp4" || ext == ".mkv" || ext == ".avi" || ext == ".webm" || ext == ".mov"
+func (m *ThumbnailManager) getVideoDuration(uri fyne.URI) (time.Duration, error) {
+	info, err := m.probeVideo(uri)
+	return info.Duration, err
+}
+
+var errCacheKeyUnknown = errors.New("cache key needs the content")
+
+// knownCacheKey is cacheKey without reading the content of other URIs. It
+// fails for URIs keyed by content that have not been read this session.
+func (m *ThumbnailManager) knownCacheKey(uri fyne.URI) (string, error) {
+	if uri.Scheme() != "file" {
+		if repo, err := repository.ForURI(uri); err == nil {
+			if _, ok := repo.(ThumbnailKeyer); ok {
+				return m.cacheKey(uri)
+			}
+		}
+		if key, ok := m.uriKeys.Load(uri.String()); ok {
+			return key.(string), nil
+		}
+		return "", errCacheKeyUnknown
+	}
+	return m.cacheKey(uri)
+}
+
+// cacheKey returns the disk cache key for uri. Local files are keyed by path,
+// size, modification time and leading content; other URIs ask their repository
+// for a ThumbnailKey, falling back to hashing the streamed content.
+func (m *ThumbnailManager) cacheKey(uri fyne.URI) (string, error) {
+	if uri.Scheme() == "file" {
+		return m.generateCacheKey(uri.Path())
+	}
+
+	id := uri.String()
+	if repo, err := repository.ForURI(uri); err == nil {
+		if keyer, ok := repo.(ThumbnailKeyer); ok {
+			key, err := keyer.ThumbnailKey(uri)
+			if err != nil {
+				return "", err
+			}
+			sum := sha256.Sum256([]byte(id + "\x00" + key))
+			return hex.EncodeToString(sum[:]), nil
+		}
+	}
+
+	// Hashing the content is expensive, so remember keys for this session.
+	if key, ok := m.uriKeys.Load(id); ok {
+		return key.(string), nil
+	}
+	r, err := storage.Reader(uri)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	h := contentKeyHash(uri)
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	key := hex.EncodeToString(h.Sum(nil))
+	m.uriKeys.Store(id, key)
+	return key, nil
 }
 
 func (m *ThumbnailManager) generateCacheKey(path string) (string, error) {
@@ -358,6 +909,9 @@ func (m *ThumbnailManager) generateCacheKey(path string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if info.IsDir() {
+		return m.folderCacheKey(absPath)
+	}
 
 	h := sha256.New()
 	// Key factor 1 & 2: Path and ModTime
@@ -421,12 +975,29 @@ func (m *ThumbnailManager) cleanupCache() {
 		return cachedFiles[i].time.Before(cachedFiles[j].time)
 	})
 
+	sizes := make(map[string]int64, len(cachedFiles))
 	for _, f := range cachedFiles {
-		if totalSize <= int64(float64(MaxCacheSize)*0.8) && len(cachedFiles) <= int(float64(MaxCacheFiles)*0.8) {
+		sizes[f.name] = f.size
+	}
+	remove := func(name string) {
+		size, ok := sizes[name]
+		if !ok {
+			return
+		}
+		delete(sizes, name)
+		_ = os.Remove(filepath.Join(m.cacheDir, name))
+		_ = os.Remove(filepath.Join(m.cacheDir, strings.TrimSuffix(name, ".jpg")+".json"))
+		totalSize -= size
+	}
+
+	for _, f := range cachedFiles {
+		if totalSize <= int64(float64(MaxCacheSize)*0.8) && len(sizes) <= int(float64(MaxCacheFiles)*0.8) {
 			break
 		}
-		_ = os.Remove(filepath.Join(m.cacheDir, f.name))
-		totalSize -= f.size
-		cachedFiles = cachedFiles[1:]
+		remove(f.name)
+		// A storyboard sheet goes with the thumbnail of the same video.
+		if base := strings.TrimSuffix(f.name, ".jpg"); !strings.HasSuffix(base, storyboardSuffix) {
+			remove(base + storyboardSuffix + ".jpg")
+		}
 	}
 }
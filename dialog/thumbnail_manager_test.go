@@ -3,6 +3,9 @@ package dialog
 import (
 	"fmt"
 	"image"
+	"image/color"
+	"image/draw"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"sync"
@@ -102,3 +105,254 @@ func TestThumbnailManager_Video_AspectRatio(t *testing.T) {
 
 	fmt.Printf("Thumbnail generated successfully with letterboxing: %dx%d\n", bounds.Dx(), bounds.Dy())
 }
+
+func TestVideoFrameCandidates(t *testing.T) {
+	const duration = 100 * time.Second
+
+	middle := videoFrameCandidates(ThumbnailOptions{VideoFrameSamples: 3}, duration)
+	if len(middle) != 3 || middle[0] != duration/2 {
+		t.Fatalf("expected middle strategy to start at the centre, got %v", middle)
+	}
+	for _, at := range middle {
+		if at < 20*time.Second || at > 80*time.Second {
+			t.Errorf("expected middle candidates within the middle 60%%, got %v", at)
+		}
+	}
+
+	first := videoFrameCandidates(ThumbnailOptions{VideoFrameStrategy: VideoFrameFirstNonBlack, VideoFrameSamples: 4}, duration)
+	if len(first) != 4 || first[0] != 20*time.Second {
+		t.Fatalf("unexpected first-non-black candidates: %v", first)
+	}
+	for i := 1; i < len(first); i++ {
+		if first[i] <= first[i-1] {
+			t.Fatalf("expected ascending candidates, got %v", first)
+		}
+	}
+
+	fixed := videoFrameCandidates(ThumbnailOptions{VideoFrameStrategy: VideoFrameFixedOffset, VideoFrameOffset: 10 * time.Second}, duration)
+	if len(fixed) != defaultVideoFrameSamples || fixed[0] != 10*time.Second {
+		t.Fatalf("unexpected fixed-offset candidates: %v", fixed)
+	}
+
+	if got := videoFrameCandidates(ThumbnailOptions{}, 0); len(got) != 1 || got[0] != 0 {
+		t.Fatalf("expected a single zero candidate for unknown duration, got %v", got)
+	}
+}
+
+func TestFrameLuminance_DetectsBlankFrames(t *testing.T) {
+	black := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	draw.Draw(black, black.Bounds(), &image.Uniform{color.Black}, image.Point{}, draw.Src)
+	if mean, variance := frameLuminance(black); !isBlankFrame(mean, variance) {
+		t.Errorf("expected black frame to be blank (mean %.1f, variance %.1f)", mean, variance)
+	}
+
+	grey := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	draw.Draw(grey, grey.Bounds(), &image.Uniform{color.Gray{Y: 128}}, image.Point{}, draw.Src)
+	if mean, variance := frameLuminance(grey); !isBlankFrame(mean, variance) {
+		t.Errorf("expected flat grey frame to be blank (mean %.1f, variance %.1f)", mean, variance)
+	}
+
+	checker := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			if (x/4+y/4)%2 == 0 {
+				checker.Set(x, y, color.White)
+			} else {
+				checker.Set(x, y, color.Black)
+			}
+		}
+	}
+	mean, variance := frameLuminance(checker)
+	if isBlankFrame(mean, variance) {
+		t.Errorf("expected detailed frame to be usable (mean %.1f, variance %.1f)", mean, variance)
+	}
+	if _, greyVariance := frameLuminance(grey); variance <= greyVariance {
+		t.Errorf("expected detailed frame to score higher than flat frame")
+	}
+}
+
+func TestPickVideoFrame_StopsAtDetailedFrame(t *testing.T) {
+	checker := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			if (x/4+y/4)%2 == 0 {
+				checker.Set(x, y, color.White)
+			} else {
+				checker.Set(x, y, color.Black)
+			}
+		}
+	}
+	// Low contrast noise: not blank, but not detailed enough to stop on.
+	dull := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			dull.Set(x, y, color.Gray{Y: uint8(110 + (x+y)%2*20)})
+		}
+	}
+	candidates := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}
+
+	calls := 0
+	img, err := pickVideoFrame(candidates, VideoFrameMiddle, func(time.Duration) (image.Image, error) {
+		calls++
+		return checker, nil
+	})
+	if err != nil || img != image.Image(checker) {
+		t.Fatalf("expected the detailed frame, got %v, %v", img, err)
+	}
+	if calls != 1 {
+		t.Errorf("expected a single extraction for a detailed centre frame, got %d", calls)
+	}
+
+	calls = 0
+	frames := []image.Image{dull, checker, dull}
+	img, err = pickVideoFrame(candidates, VideoFrameMiddle, func(time.Duration) (image.Image, error) {
+		calls++
+		return frames[calls-1], nil
+	})
+	if err != nil || img != image.Image(checker) || calls != 2 {
+		t.Errorf("expected to stop at the second, detailed frame; got %d extractions", calls)
+	}
+
+	calls = 0
+	img, err = pickVideoFrame(candidates, VideoFrameMiddle, func(time.Duration) (image.Image, error) {
+		calls++
+		return dull, nil
+	})
+	if err != nil || img != image.Image(dull) || calls != len(candidates) {
+		t.Errorf("expected every candidate to be tried when none is detailed, got %d extractions", calls)
+	}
+
+	calls = 0
+	_, err = pickVideoFrame(candidates, VideoFrameFirstNonBlack, func(time.Duration) (image.Image, error) {
+		calls++
+		return dull, nil
+	})
+	if err != nil || calls != 1 {
+		t.Errorf("expected first-non-black to stop at the first usable frame, got %d extractions", calls)
+	}
+}
+
+func TestStoryboard_FromSheetMapsPositionToFrame(t *testing.T) {
+	const frames = 4
+	sheet := image.NewRGBA(image.Rect(0, 0, frames*16, 16))
+	for i := 0; i < frames; i++ {
+		c := color.RGBA{R: uint8(i * 60), A: 255}
+		draw.Draw(sheet, image.Rect(i*16, 0, (i+1)*16, 16), &image.Uniform{c}, image.Point{}, draw.Src)
+	}
+
+	sb := newStoryboardFromSheet(sheet)
+	if sb == nil || len(sb.frames) != frames {
+		t.Fatalf("expected %d frames, got %v", frames, sb)
+	}
+
+	tests := []struct {
+		ratio float32
+		want  int
+	}{
+		{ratio: -0.5, want: 0},
+		{ratio: 0, want: 0},
+		{ratio: 0.3, want: 1},
+		{ratio: 0.99, want: 3},
+		{ratio: 1.5, want: 3},
+	}
+	for _, tc := range tests {
+		frame := sb.frameAt(tc.ratio)
+		b := frame.Bounds()
+		r, _, _, _ := frame.At(b.Min.X, b.Min.Y).RGBA()
+		if got := int(r>>8) / 60; got != tc.want {
+			t.Errorf("frameAt(%.2f) returned frame %d, want %d", tc.ratio, got, tc.want)
+		}
+	}
+
+	if newStoryboardFromSheet(image.NewRGBA(image.Rect(0, 0, 8, 16))) != nil {
+		t.Error("expected sheet narrower than one cell to be rejected")
+	}
+}
+
+func TestParseFFmpegInfo(t *testing.T) {
+	out := `Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'clip.mp4':
+  Duration: 01:02:03.45, start: 0.000000, bitrate: 1205 kb/s
+  Stream #0:0[0x1](und): Video: h264 (High) (avc1 / 0x31637661), yuv420p(tv, bt709, progressive), 1920x1080 [SAR 1:1 DAR 16:9], 1070 kb/s, 25 fps
+  Stream #0:1[0x2](und): Audio: aac (LC) (mp4a / 0x6134706D), 48000 Hz, stereo, fltp, 128 kb/s`
+
+	info, err := parseFFmpegInfo(out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := time.Hour + 2*time.Minute + 3*time.Second + 450*time.Millisecond
+	if info.Duration != want {
+		t.Errorf("expected duration %v, got %v", want, info.Duration)
+	}
+	if info.Format != "H264" {
+		t.Errorf("expected format H264, got %q", info.Format)
+	}
+	if info.Width != 1920 || info.Height != 1080 {
+		t.Errorf("expected 1920x1080, got %dx%d", info.Width, info.Height)
+	}
+
+	if _, err := parseFFmpegInfo("no such file"); err == nil {
+		t.Error("expected error when duration is missing")
+	}
+}
+
+func TestFormatMediaDuration(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{in: 0, want: ""},
+		{in: 9 * time.Second, want: "0:09"},
+		{in: 2*time.Minute + 5*time.Second, want: "2:05"},
+		{in: time.Hour + 2*time.Minute + 3*time.Second, want: "1:02:03"},
+	}
+	for _, tc := range tests {
+		if got := formatMediaDuration(tc.in); got != tc.want {
+			t.Errorf("formatMediaDuration(%v) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestMediaInfo_SidecarRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key.json")
+	in := mediaInfo{Width: 640, Height: 480, Duration: 90 * time.Second, Format: "VP9"}
+	if err := writeMediaInfo(path, in); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	out, err := readMediaInfo(path)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if out != in {
+		t.Fatalf("expected %+v, got %+v", in, out)
+	}
+	if got := out.resolution(); got != "640×480" {
+		t.Errorf("unexpected resolution %q", got)
+	}
+}
+
+func TestThumbnailManager_LoadStoryboardAlwaysCallsBack(t *testing.T) {
+	dir := t.TempDir()
+	video := filepath.Join(dir, "broken.mp4")
+	if err := os.WriteFile(video, []byte{0x00, 0x01, 0x02, 0x03}, 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	// Without FFmpeg no storyboard can be generated.
+	tm := &ThumbnailManager{}
+
+	results := make(chan *storyboard, 3)
+	for range 2 {
+		tm.loadStoryboard(storage.NewFileURI(video), func(sb *storyboard) { results <- sb })
+	}
+	tm.loadStoryboard(storage.NewFileURI(filepath.Join(dir, "notes.txt")), func(sb *storyboard) { results <- sb })
+
+	for range 3 {
+		select {
+		case sb := <-results:
+			if sb != nil {
+				t.Errorf("expected a nil storyboard on failure, got %v", sb)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected every caller to be called back, including the waiting one")
+		}
+	}
+}
@@ -17,6 +17,17 @@ const (
 	GridView
 )
 
+// SelectionOrder can be passed to SetSelectionOrder() to choose the order
+// selected items are returned and listed in.
+type SelectionOrder int
+
+const (
+	// SelectionOrderList returns selected items in the order they are listed.
+	SelectionOrderList SelectionOrder = iota
+	// SelectionOrderClick returns selected items in the order they were selected.
+	SelectionOrderClick
+)
+
 const (
 	fileIconSize       = 64
 	fileInlineIconSize = 24
@@ -25,6 +36,10 @@ const (
 	ffmpegPathKey      = "fyne:fileDialogFFmpegPath"
 	showHiddenKey      = "fyne:fileDialogShowHidden"
 	zoomLevelKey       = "fyne:fileDialogZoomLevel"
+	previewVisibleKey  = "fyne:fileDialogPreviewVisible"
+	previewWidthKey    = "fyne:fileDialogPreviewWidth"
+	windowWidthKey     = "fyne:fileDialogWindowWidth"
+	windowHeightKey    = "fyne:fileDialogWindowHeight"
 )
 
 type favoriteItem struct {
@@ -29,6 +29,17 @@ func clampZoomLevelIndex(i int) int {
 	return i
 }
 
+// nearestZoomLevelIndex returns the zoom level closest to scale.
+func nearestZoomLevelIndex(scale float32) int {
+	best := defaultZoomLevelIndex
+	for i, level := range zoomLevels {
+		if math.Abs(float64(level-scale)) < math.Abs(float64(zoomLevels[best]-scale)) {
+			best = i
+		}
+	}
+	return best
+}
+
 func isZoomModifierActive() bool {
 	d, ok := fyne.CurrentApp().Driver().(desktop.Driver)
 	if !ok {